@@ -9,6 +9,12 @@ import (
 type Runner interface {
 	Build() error
 	Clear() error
+	Check() error
+	Migrate() error
+	Docs(outputDir string) error
+	ExportSchema(name, output string) error
+	ImportSchema(path, name string) error
+	LintSchemas(name string) error
 }
 
 // Run executes the command-line interface.
@@ -23,7 +29,49 @@ func Run(g Runner, args []string) error {
 		return g.Build()
 	case "clear":
 		return g.Clear()
+	case "check":
+		return g.Check()
+	case "migrate":
+		return g.Migrate()
+	case "docs":
+		outputDir := ""
+		if len(args) > 2 {
+			outputDir = args[2]
+		}
+		return g.Docs(outputDir)
+	case "schema":
+		return runSchema(g, args)
 	default:
-		return fmt.Errorf("unknown command: %s\nUsage: %s [build|clear]", command, args[0])
+		return fmt.Errorf("unknown command: %s\nUsage: %s [build|clear|check|migrate|docs|schema]", command, args[0])
+	}
+}
+
+// runSchema executes the schema subcommands.
+func runSchema(g Runner, args []string) error {
+	usage := fmt.Sprintf("Usage: %s schema export <name> [output.json]\n       %s schema import <input.json> [name]\n       %s schema lint [name]", args[0], args[0], args[0])
+	if len(args) < 3 {
+		return fmt.Errorf("missing arguments\n%s", usage)
+	}
+
+	subcommand, target, extra := strings.ToLower(args[2]), "", ""
+	if len(args) > 3 {
+		target = args[3]
+	}
+	if len(args) > 4 {
+		extra = args[4]
+	}
+	if target == "" && subcommand != "lint" {
+		return fmt.Errorf("missing arguments\n%s", usage)
+	}
+
+	switch subcommand {
+	case "export":
+		return g.ExportSchema(target, extra)
+	case "import":
+		return g.ImportSchema(target, extra)
+	case "lint":
+		return g.LintSchemas(target)
+	default:
+		return fmt.Errorf("unknown schema command: %s\n%s", subcommand, usage)
 	}
 }
@@ -22,11 +22,27 @@ const (
 
 // Config holds the application configuration.
 type Config struct {
-	AssetsDir   string
-	PagesDir    string
+	AssetsDir string
+	PagesDir  string
+	// JournalsDir is the Logseq journals directory records may add blocks
+	// to. If empty, journal blocks are not generated.
+	JournalsDir string
 	TemplateDir string
 	SchemaDir   string
 	ProjectRoot string
+
+	// EnumPages enables generating a page for every enum value.
+	EnumPages bool
+	// PropertyPages enables generating a page for every schema property.
+	PropertyPages bool
+	// SchemaDocs enables generating a documentation page for every schema on build.
+	SchemaDocs bool
+	// NamespacePages enables generating an index page for every folder that
+	// contains records but has no index.ini of its own.
+	NamespacePages bool
+	// NamespaceTemplate names the template namespace pages are rendered with.
+	// If empty, a list of the children and their properties is generated.
+	NamespaceTemplate string
 }
 
 // Load finds and loads the configuration from a generate.ini file.
@@ -58,6 +74,7 @@ func Load() (*Config, error) {
 
 	inputPath := cfg.Section("input").Key("path").String()
 	outputPath := cfg.Section("output").Key("path").String()
+	journalsPath := cfg.Section("journals").Key("path").String()
 	templatePath := cfg.Section("template").Key("path").String()
 	schemaPath := cfg.Section("schema").Key("path").String()
 
@@ -65,12 +82,25 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("input.path, output.path, or template.path not set in %s", iniPath)
 	}
 
+	var journalsDir string
+	if journalsPath != "" {
+		journalsDir = filepath.Join(projectRoot, journalsPath)
+	}
+
+	generateSection := cfg.Section("generate")
+
 	return &Config{
-		AssetsDir:   filepath.Join(projectRoot, inputPath),
-		PagesDir:    filepath.Join(projectRoot, outputPath),
-		TemplateDir: filepath.Join(projectRoot, templatePath),
-		SchemaDir:   filepath.Join(projectRoot, schemaPath),
-		ProjectRoot: projectRoot,
+		AssetsDir:         filepath.Join(projectRoot, inputPath),
+		PagesDir:          filepath.Join(projectRoot, outputPath),
+		JournalsDir:       journalsDir,
+		TemplateDir:       filepath.Join(projectRoot, templatePath),
+		SchemaDir:         filepath.Join(projectRoot, schemaPath),
+		ProjectRoot:       projectRoot,
+		EnumPages:         generateSection.Key("enum_pages").MustBool(false),
+		PropertyPages:     generateSection.Key("property_pages").MustBool(false),
+		SchemaDocs:        generateSection.Key("schema_docs").MustBool(false),
+		NamespacePages:    generateSection.Key("namespace_pages").MustBool(false),
+		NamespaceTemplate: generateSection.Key("namespace_template").String(),
 	}, nil
 }
 
@@ -28,6 +28,12 @@ path = my_assets
 path = my_pages
 [template]
 path = my_templates
+[journals]
+path = my_journals
+[generate]
+enum_pages = true
+namespace_pages = true
+namespace_template = namespace
 `
 		iniPath := filepath.Join(tempDir, "generate.ini")
 		err = os.WriteFile(iniPath, []byte(iniContent), 0644)
@@ -55,6 +61,11 @@ path = my_templates
 		assert.Equal(t, filepath.Join(expectedRoot, "my_assets"), cfg.AssetsDir)
 		assert.Equal(t, filepath.Join(expectedRoot, "my_pages"), cfg.PagesDir)
 		assert.Equal(t, filepath.Join(expectedRoot, "my_templates"), cfg.TemplateDir)
+		assert.Equal(t, filepath.Join(expectedRoot, "my_journals"), cfg.JournalsDir)
+		assert.True(t, cfg.EnumPages)
+		assert.False(t, cfg.PropertyPages)
+		assert.True(t, cfg.NamespacePages)
+		assert.Equal(t, "namespace", cfg.NamespaceTemplate)
 	})
 
 	t.Run("returns defaults when generate.ini is not found", func(t *testing.T) {
@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// blockSectionPrefix starts the names of index.ini sections that hold a
+// block, e.g. [block.summary].
+const blockSectionPrefix = "block."
+
+// blockNamespace is the namespace of the name-based UUIDs of generated
+// blocks.
+var blockNamespace = [16]byte{0x5e, 0x0b, 0x7c, 0x61, 0x2d, 0x3a, 0x4f, 0x8e, 0x9b, 0x15, 0x6c, 0x0d, 0x47, 0xa2, 0xe3, 0x19}
+
+// recordBlock is a block a record adds below its page content.
+type recordBlock struct {
+	// key identifies the block within its page; its id is derived from it.
+	key     string
+	content string
+	// keys lists the block properties in file order.
+	keys       []string
+	properties map[string]string
+}
+
+// blockID returns the id of the block with the given key on a page: a
+// version 5 UUID of the page path and the key, so that block references
+// survive rebuilds.
+func blockID(page, key string) string {
+	h := sha1.New()
+	h.Write(blockNamespace[:])
+	h.Write([]byte(page + "\x00" + key))
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// BlockID returns the id of the block with the given key on the current page,
+// as written by the record's blocks, for templates that emit blocks of their
+// own: id:: {{ .BlockID "summary" }}.
+func (d templateData) BlockID(key string) string {
+	return blockID(d.CurrentPath, key)
+}
+
+// iniBlocks reads the [block.<key>] sections of an index.ini.
+func iniBlocks(cfg *ini.File) ([]recordBlock, error) {
+	var blocks []recordBlock
+	for _, section := range cfg.Sections() {
+		key, ok := strings.CutPrefix(section.Name(), blockSectionPrefix)
+		if !ok {
+			continue
+		}
+		block := recordBlock{key: key, properties: make(map[string]string)}
+		for _, entry := range section.Keys() {
+			if entry.Name() == "content" {
+				block.content = entry.String()
+				continue
+			}
+			if err := checkBlockProperty(key, entry.Name()); err != nil {
+				return nil, err
+			}
+			if err := checkBlockValue(key, entry.Name(), entry.String()); err != nil {
+				return nil, err
+			}
+			block.keys = append(block.keys, entry.Name())
+			block.properties[entry.Name()] = entry.String()
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// checkBlockProperty rejects block properties the generator writes itself.
+func checkBlockProperty(block, key string) error {
+	if strings.EqualFold(key, "id") {
+		return fmt.Errorf("block '%s': property 'id' is reserved for the generated block id", block)
+	}
+	return nil
+}
+
+// checkBlockValue rejects block property values that span several lines,
+// since the generator writes block properties one per line.
+func checkBlockValue(block, key, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("block '%s': property '%s' spans several lines, which block properties cannot hold", block, key)
+	}
+	return nil
+}
+
+// addBlocks adds the blocks of the blocks section of a structured record:
+// a mapping from block key to the block's content and properties.
+func (file *recordFile) addBlocks(section []field) error {
+	for _, entry := range section {
+		for _, block := range file.blocks {
+			if block.key == entry.key {
+				return fmt.Errorf("block '%s' is set more than once", entry.key)
+			}
+		}
+		fields, ok := entry.value.([]field)
+		if !ok && entry.value != nil {
+			return fmt.Errorf("block '%s' must be a mapping", entry.key)
+		}
+		// Block properties are flattened like page properties.
+		props := &recordFile{properties: make(map[string]string)}
+		block := recordBlock{key: entry.key}
+		for _, f := range fields {
+			if f.key == "content" {
+				content, err := scalarString(f.value)
+				if err != nil {
+					return fmt.Errorf("block '%s' content: %w", entry.key, err)
+				}
+				block.content = content
+				continue
+			}
+			if err := checkBlockProperty(entry.key, f.key); err != nil {
+				return err
+			}
+			if err := props.flattenProperty(f.key, f.value); err != nil {
+				return fmt.Errorf("block '%s': %w", entry.key, err)
+			}
+		}
+		for _, key := range props.keys {
+			if err := checkBlockValue(entry.key, key, props.properties[key]); err != nil {
+				return err
+			}
+		}
+		block.keys, block.properties = props.keys, props.properties
+		file.blocks = append(file.blocks, block)
+	}
+	return nil
+}
+
+// renderBlocks writes the blocks of a record as top-level blocks, each with
+// its properties and its id.
+func renderBlocks(rec *record, out *strings.Builder) {
+	for _, block := range rec.blocks {
+		lines := strings.Split(strings.TrimRight(block.content, "\n"), "\n")
+		if lines[0] == "" {
+			lines = lines[1:]
+		}
+		for _, key := range block.keys {
+			lines = append(lines, fmt.Sprintf("%s:: %s", key, block.properties[key]))
+		}
+		lines = append(lines, "id:: "+blockID(rec.path, block.key))
+		for i, line := range lines {
+			if i == 0 {
+				out.WriteString("- " + line + "\n")
+			} else {
+				out.WriteString("  " + line + "\n")
+			}
+		}
+	}
+}
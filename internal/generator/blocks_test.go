@@ -0,0 +1,74 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_Blocks(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.TemplateDir, map[string]string{
+		"film.template": "- Quote\n  id:: {{ .BlockID \"quote\" }}\n- See {{ blockRef (blockID \"book\" \"summary\") }}",
+	})
+
+	files := map[string]string{
+		"book/index.ini": "[properties]\ntitle = Dune\n" +
+			"[block.summary]\ncontent = A desert planet.\nrating = 5\n" +
+			"[block.notes]\nstatus = todo\n",
+		"film/index.yaml": "header:\n  template: film\n" +
+			"blocks:\n  cast:\n    content: Cast\n    actors: [Paul, Leto]\n",
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	book, err := os.ReadFile(filepath.Join(cfg.PagesDir, "book.md"))
+	require.NoError(t, err)
+	uuid := `([0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12})`
+	bookPattern := regexp.MustCompile(`^generated:: true\ntitle:: Dune\n\n` +
+		`- A desert planet\.\n  rating:: 5\n  id:: ` + uuid + `\n` +
+		`- status:: todo\n  id:: ` + uuid + `\n$`)
+	match := bookPattern.FindStringSubmatch(string(book))
+	require.NotNil(t, match, string(book))
+	summaryID, notesID := match[1], match[2]
+	assert.NotEqual(t, summaryID, notesID)
+
+	film, err := os.ReadFile(filepath.Join(cfg.PagesDir, "film.md"))
+	require.NoError(t, err)
+	filmPattern := regexp.MustCompile(`^generated:: true\n\n` +
+		`- Quote\n  id:: ` + uuid + `\n` +
+		`- See \(\(` + uuid + `\)\)\n` +
+		`- Cast\n  actors:: Paul, Leto\n  id:: ` + uuid + `\n$`)
+	filmMatch := filmPattern.FindStringSubmatch(string(film))
+	require.NotNil(t, filmMatch, string(film))
+	// Templates refer to the blocks of other pages by page path and key.
+	assert.Equal(t, summaryID, filmMatch[2])
+
+	// Ids survive a clear and rebuild.
+	gen := generator.New(cfg)
+	require.NoError(t, gen.Clear())
+	require.NoError(t, gen.Build())
+	rebuilt, err := os.ReadFile(filepath.Join(cfg.PagesDir, "book.md"))
+	require.NoError(t, err)
+	assert.Equal(t, string(book), string(rebuilt))
+}
+
+func TestGenerator_Check_Blocks(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		// The generated id cannot be set by hand.
+		"book/index.ini":  "[block.summary]\ncontent = A desert planet.\nid = 1234\n",
+		"film/index.yaml": "blocks:\n  cast:\n    content: Cast\n    ID: 1234\n",
+		"valid/index.ini": "[block.summary]\ncontent = Fine\n",
+	})
+
+	err := generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 invalid record(s)")
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"logseq_gen/internal/schema"
+)
+
+// Check lints every schema and validates every record without
+// writing any pages.
+func (g *Generator) Check() error {
+	fmt.Printf("Checking schemas in %s and records in %s...\n", g.config.SchemaDir, g.config.AssetsDir)
+	g.validation = schema.NewContext()
+	issues, err := g.lintSchemas()
+	if err != nil {
+		return err
+	}
+
+	recordFiles, err := g.findRecordFiles()
+	if err != nil {
+		return fmt.Errorf("error finding record files: %w", err)
+	}
+
+	records, invalid := g.loadRecords(recordFiles)
+	g.site = newSite(records, g.config.NamespacePages)
+	for _, rec := range records {
+		var discard strings.Builder
+		if shouldSkip := g.renderRecord(rec, &discard); shouldSkip {
+			invalid++
+		} else if rec.header.has("journal") {
+			if _, err := journalDate(rec); err != nil {
+				log.Printf("[SKIP] Could not add %s to a journal: %v", rec.source, err)
+				invalid++
+			}
+		}
+	}
+
+	errors, warnings := schema.CountIssues(issues)
+	if errors > 0 || invalid > 0 {
+		return fmt.Errorf("check failed: %d schema error(s), %d invalid record(s), %d schema warning(s)", errors, invalid, warnings)
+	}
+	fmt.Printf("Check passed: %d record(s), %d schema warning(s).\n", len(records), warnings)
+	return nil
+}
+
+// LintSchemas lints the named schema, or every schema in the schema
+// directory if name is empty, and fails if any of them has errors.
+func (g *Generator) LintSchemas(name string) error {
+	var issues []schema.Issue
+	if name == "" {
+		var err error
+		if issues, err = g.schemas.LintAll(); err != nil {
+			return err
+		}
+	} else {
+		issues = g.schemas.Lint(name)
+	}
+	for _, issue := range issues {
+		log.Print(issue)
+	}
+
+	errors, warnings := schema.CountIssues(issues)
+	if errors > 0 {
+		return fmt.Errorf("lint failed: %d schema error(s), %d schema warning(s)", errors, warnings)
+	}
+	fmt.Printf("Lint passed: %d schema warning(s).\n", warnings)
+	return nil
+}
+
+// lintSchemas lints every schema in the schema directory and logs the issues.
+// Schemas with errors are marked so that records using them are skipped.
+func (g *Generator) lintSchemas() ([]schema.Issue, error) {
+	issues, err := g.schemas.LintAll()
+	if err != nil {
+		return nil, err
+	}
+
+	g.brokenSchemas = make(map[string]bool)
+	for _, issue := range issues {
+		log.Print(issue)
+		if issue.Severity == schema.SeverityError {
+			g.brokenSchemas[issue.Schema] = true
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,55 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Check(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{
+		"good.yaml": "version: 1\ntypes:\n  size:\n    type: number\n",
+		"bad.yaml":  "version: 1\ntypes:\n  size:\n    type: nubmer\n",
+	})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"ok/index.ini":       "[header]\nschema = good\n[properties]\nsize = 3\n",
+		"uses_bad/index.ini": "[header]\nschema = bad\n[properties]\nsize = 3\n",
+	})
+
+	gen := generator.New(cfg)
+
+	t.Run("Build skips records whose schema fails lint", func(t *testing.T) {
+		require.NoError(t, gen.Build())
+		assert.FileExists(t, filepath.Join(cfg.PagesDir, "ok.md"))
+		assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "uses_bad.md"))
+	})
+
+	t.Run("Check reports failures without writing pages", func(t *testing.T) {
+		require.NoError(t, gen.Clear())
+		err := gen.Check()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "check failed: 1 schema error(s), 1 invalid record(s)")
+		assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "ok.md"))
+	})
+
+	t.Run("Lint checks one schema or all of them", func(t *testing.T) {
+		assert.NoError(t, gen.LintSchemas("good"))
+		err := gen.LintSchemas("bad")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lint failed: 1 schema error(s)")
+		assert.Error(t, gen.LintSchemas(""))
+		assert.Error(t, gen.LintSchemas("missing"))
+	})
+
+	t.Run("Check passes once the schema is fixed", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(cfg.SchemaDir, "bad.yaml")))
+		require.NoError(t, os.Remove(filepath.Join(cfg.AssetsDir, "uses_bad", "index.ini")))
+		assert.NoError(t, gen.Check())
+		assert.NoError(t, gen.LintSchemas(""))
+	})
+}
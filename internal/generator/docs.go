@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"logseq_gen/internal/schema"
+)
+
+// Docs renders documentation for every schema. If outputDir is empty, each
+// schema becomes a generated Logseq page named "schema/<name>" in the pages
+// directory; otherwise a standalone markdown file is written to outputDir.
+func (g *Generator) Docs(outputDir string) error {
+	names, err := g.schemas.Names()
+	if err != nil {
+		return fmt.Errorf("could not list schemas: %w", err)
+	}
+
+	targetDir := outputDir
+	if targetDir == "" {
+		targetDir = g.config.PagesDir
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("could not create %s: %w", targetDir, err)
+	}
+
+	// Pages are written like every other generated page, so that a record
+	// page of the same name is kept. Outside a build, the generated pages on
+	// disk may be replaced.
+	if outputDir == "" && g.previous == nil {
+		previous, err := g.generatedPages()
+		if err != nil {
+			return err
+		}
+		g.previous = previous
+		defer func() { g.previous = nil }()
+	}
+
+	for _, name := range names {
+		s, err := g.getSchema(name)
+		if err != nil {
+			log.Printf("[SKIP] Schema '%s' not found or invalid: %v", name, err)
+			continue
+		}
+
+		if outputDir == "" {
+			outputFilepath := g.pageFilepath("schema/" + name)
+			if err := g.writeGeneratedPage(outputFilepath, schemaDocsPage(s)); err != nil {
+				log.Printf("[SKIP] Could not write file %s: %v", outputFilepath, err)
+			}
+			continue
+		}
+		outputFilepath := filepath.Join(outputDir, fmt.Sprintf("%s.md", name))
+		if err := g.writePage(outputFilepath, schemaDocsMarkdown(s), true); err != nil {
+			log.Printf("[SKIP] Could not write file %s: %v", outputFilepath, err)
+		}
+	}
+	return nil
+}
+
+// schemaDocsPage renders a schema as a Logseq page. The table lives in a
+// single block, so its continuation lines are indented.
+func schemaDocsPage(s *schema.Schema) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("schema:: %s\n", s.Name))
+	if s.Version != 0 {
+		content.WriteString(fmt.Sprintf("version:: %d\n", s.Version))
+	}
+	content.WriteString("\n")
+
+	lines := schemaDocsTable(s)
+	for i, line := range lines {
+		if i == 0 {
+			content.WriteString("- " + line + "\n")
+		} else {
+			content.WriteString("  " + line + "\n")
+		}
+	}
+	return content.String()
+}
+
+// schemaDocsMarkdown renders a schema as a standalone markdown document.
+func schemaDocsMarkdown(s *schema.Schema) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# %s\n\n", s.Name))
+	if s.Version != 0 {
+		content.WriteString(fmt.Sprintf("Version %d.\n\n", s.Version))
+	}
+	content.WriteString(strings.Join(schemaDocsTable(s), "\n") + "\n")
+	return content.String()
+}
+
+// tableCellReplacer escapes the characters that would break a markdown
+// table row.
+var tableCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+// schemaDocsTable returns the lines of a markdown table describing every
+// property of a schema.
+func schemaDocsTable(s *schema.Schema) []string {
+	lines := []string{
+		"| Property | Label | Type | Required | Default | Values | Description | Example |",
+		"| --- | --- | --- | --- | --- | --- | --- | --- |",
+	}
+
+	keys := make([]string, 0, len(s.Types))
+	for key := range s.Types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		typeDef := s.Types[key]
+		cells := []string{
+			"`" + key + "`",
+			typeDef.Label,
+			typeDef.Type,
+			requiredCell(typeDef),
+			valueCell(typeDef.Default),
+			enumCell(typeDef),
+			typeDef.Description,
+			valueCell(typeDef.Example),
+		}
+		for i, cell := range cells {
+			cells[i] = tableCellReplacer.Replace(strings.TrimSpace(cell))
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	return lines
+}
+
+func requiredCell(typeDef schema.Type) string {
+	switch {
+	case typeDef.Required && typeDef.Default == nil:
+		return "yes"
+	case len(typeDef.RequiredIf) > 0:
+		return "if " + typeDef.RequiredIf.String()
+	case typeDef.Compute != "":
+		return "computed"
+	default:
+		return "no"
+	}
+}
+
+func valueCell(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("`%v`", value)
+}
+
+func enumCell(typeDef schema.Type) string {
+	if typeDef.Type != "enum" {
+		return ""
+	}
+	var values []string
+	for _, name := range sortedEnumKeys(typeDef) {
+		enumKey := typeDef.Keys[name]
+		value := "`" + name + "`"
+		if enumKey.Display != "" {
+			value += " (" + enumKey.Display + ")"
+		}
+		if enumKey.Deprecated {
+			value += " deprecated"
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, ", ")
+}
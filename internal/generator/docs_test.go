@@ -0,0 +1,85 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Docs(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 2\n" +
+		"types:\n" +
+		"  isbn:\n" +
+		"    label: ISBN\n" +
+		"    type: string\n" +
+		"    required: true\n" +
+		"    description: 13-digit book number\n" +
+		"    example: 9780441013593\n" +
+		"  notes:\n" +
+		"    type: string\n" +
+		"    description: |\n" +
+		"      Free text,\n" +
+		"      kept as written\n" +
+		"  status:\n" +
+		"    type: enum\n" +
+		"    default: open\n" +
+		"    description: Reading state a|b\n" +
+		"    keys:\n" +
+		"      open:\n" +
+		"        display: Open\n" +
+		"      done: {}\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": schemaContent})
+
+	table := "| Property | Label | Type | Required | Default | Values | Description | Example |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| `isbn` | ISBN | string | yes |  |  | 13-digit book number | `9780441013593` |\n" +
+		"| `notes` |  | string | no |  |  | Free text,<br>kept as written |  |\n" +
+		"| `status` |  | enum | no | `open` | `done`, `open` (Open) | Reading state a\\|b |  |\n"
+
+	gen := generator.New(cfg)
+
+	t.Run("Standalone markdown", func(t *testing.T) {
+		outputDir := filepath.Join(t.TempDir(), "docs")
+		require.NoError(t, gen.Docs(outputDir))
+		content, err := os.ReadFile(filepath.Join(outputDir, "book.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# book\n\nVersion 2.\n\n"+table, string(content))
+	})
+
+	t.Run("Logseq page", func(t *testing.T) {
+		require.NoError(t, gen.Docs(""))
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "schema___book.md"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "generated:: true\nschema:: book\nversion:: 2\n\n- | Property | Label |"))
+		assert.Contains(t, string(content), "\n  | `isbn` | ISBN | string | yes |")
+
+		// Regenerating replaces the generated page.
+		require.NoError(t, gen.Docs(""))
+	})
+
+	t.Run("Build regenerates docs when enabled", func(t *testing.T) {
+		cfg.SchemaDocs = true
+		require.NoError(t, gen.Build())
+		assert.FileExists(t, filepath.Join(cfg.PagesDir, "schema___book.md"))
+	})
+
+	t.Run("Record pages are kept", func(t *testing.T) {
+		writeFiles(t, cfg.AssetsDir, map[string]string{
+			"schema/book/index.ini": "[header]\ntemplate = page\n[properties]\ntitle = Mine\n",
+		})
+		writeFiles(t, cfg.TemplateDir, map[string]string{"page.template": "- {{ .Properties.title }}\n"})
+
+		require.NoError(t, gen.Build())
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "schema___book.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "title:: Mine")
+		assert.NotContains(t, string(content), "| Property |")
+	})
+}
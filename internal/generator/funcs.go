@@ -0,0 +1,178 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"logseq_gen/internal/schema"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// blockPropertyPattern matches a block property line such as "id:: value".
+var blockPropertyPattern = regexp.MustCompile(`^[^\s:]+:: `)
+
+// templateFuncs returns the helpers available to every template. Arguments
+// are ordered so that the value being formatted comes last and can be piped
+// in, e.g. {{ .Raw.title | slug }} or {{ .Values.tags | join ", " }}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"pageRef":  pageRef,
+		"tag":      tag,
+		"embed":    embed,
+		"blockRef": blockRef,
+		"blockID":  blockID,
+		"date":     formatDate,
+		"slug":     schema.Slug,
+		"join":     join,
+		"split":    split,
+		"default":  defaultValue,
+		"indent":   indent,
+		"query":    query,
+	}
+}
+
+// pageRef renders a link to a page: [[name]].
+func pageRef(name string) string {
+	return fmt.Sprintf("[[%s]]", name)
+}
+
+// tag renders a tag, using the bracketed form when the name contains
+// characters that would end a plain tag: #name or #[[some name]].
+func tag(name string) string {
+	if strings.ContainsAny(name, " \t,#[]()") {
+		return fmt.Sprintf("#[[%s]]", name)
+	}
+	return "#" + name
+}
+
+// embed renders an embed of a block (given its UUID) or of a page.
+func embed(target string) string {
+	if uuidPattern.MatchString(target) {
+		return fmt.Sprintf("{{embed ((%s))}}", target)
+	}
+	return fmt.Sprintf("{{embed [[%s]]}}", target)
+}
+
+// blockRef renders a reference to a block: ((uuid)).
+func blockRef(uuid string) string {
+	return fmt.Sprintf("((%s))", uuid)
+}
+
+// formatDate formats a time.Time or a YYYY-MM-DD string with a Go layout.
+func formatDate(layout string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout), nil
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return "", fmt.Errorf("date: '%s' is not a valid date in YYYY-MM-DD format", v)
+		}
+		return t.Format(layout), nil
+	default:
+		return "", fmt.Errorf("date: unsupported value of type %T", value)
+	}
+}
+
+// join concatenates the elements of any slice with sep. Elements are
+// formatted with fmt, so enum values print their display name.
+func join(sep string, list interface{}) (string, error) {
+	if s, ok := list.(string); ok {
+		return s, nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: unsupported value of type %T", list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// split splits s on sep and trims the surrounding space of each element.
+func split(sep, s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, sep)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// defaultValue returns value, or def if value is empty or the zero value.
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	default:
+		if v.IsZero() {
+			return def
+		}
+	}
+	return value
+}
+
+// indent nests text level blocks deeper in the Logseq outline. Lines that
+// are not blocks yet become blocks of their own, except for the lines of a
+// #+BEGIN_ ... #+END_ section and block properties, which continue the block
+// above them.
+func indent(level int, text string) string {
+	prefix := strings.Repeat("\t", level)
+	lines := strings.Split(text, "\n")
+	continuation := ""
+	inSection := false
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		body := strings.TrimLeft(line, "\t")
+		tabs := line[:len(line)-len(body)]
+		content := strings.TrimSpace(strings.TrimPrefix(body, "-"))
+		switch {
+		case body == "-" || strings.HasPrefix(body, "- "):
+			lines[i] = prefix + line
+			continuation = prefix + tabs + "  "
+		case strings.HasPrefix(body, "  "):
+			lines[i] = prefix + line
+		case continuation != "" && (inSection || blockPropertyPattern.MatchString(body)):
+			lines[i] = continuation + body
+		default:
+			lines[i] = prefix + tabs + "- " + body
+			continuation = prefix + tabs + "  "
+		}
+		if strings.HasPrefix(content, "#+BEGIN_") {
+			inSection = true
+		} else if strings.HasPrefix(content, "#+END_") {
+			inSection = false
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// query renders a Logseq query. An EDN map such as {:query [...]} becomes an
+// advanced query block; anything else becomes a simple {{query ...}}.
+func query(q string) string {
+	q = strings.TrimSpace(q)
+	if strings.HasPrefix(q, "{") {
+		return fmt.Sprintf("#+BEGIN_QUERY\n%s\n#+END_QUERY", q)
+	}
+	return fmt.Sprintf("{{query %s}}", q)
+}
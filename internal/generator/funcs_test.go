@@ -0,0 +1,90 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_TemplateFuncs(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  date:\n" +
+		"    type: date\n" +
+		"  genre:\n" +
+		"    type: enum\n" +
+		"    keys:\n" +
+		"      scifi:\n" +
+		"        display: Science Fiction\n" +
+		"      classic:\n" +
+		"        display: Classic\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": schemaContent})
+
+	templateContent := `- {{ pageRef .CurrentPath }} {{ tag "book" }} {{ tag "to read" }}
+- {{ embed (printf "%s/content" .CurrentPath) }}
+- {{ embed "65f1c2a0-1234-4abc-8def-0123456789ab" }} {{ blockRef "65f1c2a0-1234-4abc-8def-0123456789ab" }}
+- {{ .Values.date | date "Jan 2, 2006" }} / {{ .Raw.date | date "2006" }}
+- {{ .Raw.title | slug }}
+- {{ .Values.genre | join ", " }}
+- {{ range split "," .Raw.authors }}[{{ . }}]{{ end }}
+- {{ .Raw.subtitle | default "n/a" }}
+- Notes
+{{ indent 1 .Raw.notes }}
+- Summary
+{{ indent 1 .Raw.summary }}
+- Books
+{{ indent 1 (query "{:query [:find (pull ?p [*]) :where [?p :block/name]]}") }}
+- {{ query "(page-property genre \"Classic\")" }}
+- {{ query "{:query [:find (pull ?p [*]) :where [?p :block/name]]}" }}
+`
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": templateContent})
+
+	iniContent := "[header]\nschema = book\ntemplate = book\n" +
+		"[properties]\n" +
+		"title = Dune: Deluxe Edition\n" +
+		"date = 1965-08-01\n" +
+		"genre = scifi, classic\n" +
+		"authors = Frank Herbert, Brian Herbert\n" +
+		"notes = \"\"\"- first\n\t- nested\n- second\"\"\"\n" +
+		"summary = \"\"\"A desert planet.\nSpice.\"\"\"\n"
+	writeFiles(t, cfg.AssetsDir, map[string]string{"books/dune/index.ini": iniContent})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "books___dune.md"))
+	require.NoError(t, err)
+	body := string(content)[len("generated:: true\n"):]
+	body = body[len(body)-len(expectedFuncsBody):]
+	assert.Equal(t, expectedFuncsBody, body)
+}
+
+const expectedFuncsBody = `- [[books/dune]] #book #[[to read]]
+- {{embed [[books/dune/content]]}}
+- {{embed ((65f1c2a0-1234-4abc-8def-0123456789ab))}} ((65f1c2a0-1234-4abc-8def-0123456789ab))
+- Aug 1, 1965 / 1965
+- dune-deluxe-edition
+- Science Fiction, Classic
+- [Frank Herbert][Brian Herbert]
+- n/a
+- Notes
+	- first
+		- nested
+	- second
+- Summary
+	- A desert planet.
+	- Spice.
+- Books
+	- #+BEGIN_QUERY
+	  {:query [:find (pull ?p [*]) :where [?p :block/name]]}
+	  #+END_QUERY
+- {{query (page-property genre "Classic")}}
+- #+BEGIN_QUERY
+{:query [:find (pull ?p [*]) :where [?p :block/name]]}
+#+END_QUERY
+`
@@ -4,15 +4,14 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
-	"gopkg.in/ini.v1"
-
 	"logseq_gen/internal/config"
 	"logseq_gen/internal/schema"
 )
@@ -23,7 +22,23 @@ const generatedMarker = "generated:: true"
 type Generator struct {
 	config        *config.Config
 	templateCache map[string]*template.Template
-	schemaCache   map[string]*schema.Schema
+	// templateFiles holds every template file, parsed on first use.
+	templateFiles map[string]*templateFile
+	schemas       *schema.Registry
+	validation    *schema.Context
+	brokenSchemas map[string]bool
+	// site indexes the records of the current build for templates.
+	site *site
+	// previous holds the generated pages of the previous build that the
+	// current build has not written yet.
+	previous map[string]bool
+	// manifest holds the rows of the previous build and rows those of the
+	// current one.
+	manifest rowManifest
+	rows     rowManifest
+	// sharedInputs is the hash of the schemas and templates, computed once
+	// per build.
+	sharedInputs string
 }
 
 // New creates a new Generator.
@@ -31,27 +46,62 @@ func New(cfg *config.Config) *Generator {
 	return &Generator{
 		config:        cfg,
 		templateCache: make(map[string]*template.Template),
-		schemaCache:   make(map[string]*schema.Schema),
+		schemas:       schema.NewRegistry(cfg.SchemaDir),
+		validation:    schema.NewContext(),
+		brokenSchemas: make(map[string]bool),
+		site:          newSite(nil, false),
 	}
 }
 
-// Build generates markdown pages from index.ini files.
+// Build generates markdown pages from record files. Records are rendered
+// again, except rows whose hash has not changed since the previous build, and
+// pages whose content did not change are not rewritten. Generated pages that
+// are no longer produced are removed at the end. If the build stops early,
+// the pages of the previous build are kept as they are.
 func (g *Generator) Build() error {
-	if err := g.Clear(); err != nil {
+	previous, err := g.generatedPages()
+	if err != nil {
 		return err
 	}
+	g.previous = previous
+	defer func() { g.previous = nil }()
+	g.manifest = g.loadManifest()
+	g.rows = rowManifest{Rows: map[string]rowEntry{}}
+	g.sharedInputs = ""
+	defer func() { g.manifest, g.rows = rowManifest{}, rowManifest{} }()
 	if err := os.MkdirAll(g.config.PagesDir, 0755); err != nil {
 		return fmt.Errorf("could not create pages directory: %w", err)
 	}
 
 	fmt.Printf("\nStarting build process from %s...\n", g.config.AssetsDir)
-	iniFiles, err := g.findIniFiles()
+	g.validation = schema.NewContext()
+	if _, err := g.lintSchemas(); err != nil {
+		return err
+	}
+	recordFiles, err := g.findRecordFiles()
 	if err != nil {
-		return fmt.Errorf("error finding ini files: %w", err)
+		return fmt.Errorf("error finding record files: %w", err)
 	}
 
-	for _, iniPath := range iniFiles {
-		g.processIniFile(iniPath)
+	// All records are loaded before any is rendered, so that templates can
+	// navigate the whole tree.
+	records, _ := g.loadRecords(recordFiles)
+	g.site = newSite(records, g.config.NamespacePages)
+	g.rows.Site = g.site.fingerprint()
+	for _, rec := range records {
+		g.writeRecord(rec)
+	}
+	g.generateNamespacePages()
+	g.generateJournals(records)
+	g.generateSchemaPages()
+	if g.config.SchemaDocs {
+		if err := g.Docs(""); err != nil {
+			return err
+		}
+	}
+	g.removeStalePages()
+	if err := g.saveManifest(); err != nil {
+		return fmt.Errorf("could not write %s: %w", manifestFile, err)
 	}
 	fmt.Println("\nBuild process finished.")
 	return nil
@@ -65,24 +115,14 @@ func (g *Generator) Clear() error {
 	}
 
 	fmt.Printf("Clearing generated files from %s...\n", g.config.PagesDir)
-	files, err := filepath.Glob(filepath.Join(g.config.PagesDir, "*.md"))
+	previous, err := g.generatedPages()
 	if err != nil {
-		return fmt.Errorf("error finding markdown files: %w", err)
+		return err
 	}
-
-	for _, file := range files {
-		generated, err := g.isGeneratedFile(file)
-		if err != nil {
-			log.Printf("Error checking if file %s is generated: %v", file, err)
-			continue
-		}
-		if generated {
-			if err := os.Remove(file); err != nil {
-				log.Printf("Error removing file %s: %v", file, err)
-			} else {
-				fmt.Printf("Removed %s\n", filepath.Base(file))
-			}
-		}
+	g.previous = previous
+	g.removeStalePages()
+	if err := os.Remove(filepath.Join(g.config.PagesDir, manifestFile)); err != nil && !os.IsNotExist(err) {
+		return err
 	}
 	fmt.Println("Clear build finished.")
 	return nil
@@ -103,185 +143,387 @@ func (g *Generator) isGeneratedFile(path string) (bool, error) {
 	return false, scanner.Err()
 }
 
-// findIniFiles finds all index.ini files in the assets directory.
-func (g *Generator) findIniFiles() ([]string, error) {
-	var iniFiles []string
-	err := filepath.Walk(g.config.AssetsDir, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && info.Name() == "index.ini" {
-			iniFiles = append(iniFiles, path)
-		}
-		return nil
-	})
-	return iniFiles, err
+// record is a page's worth of properties, loaded and validated. Most records
+// come from a record file such as index.ini; tabular sources produce one per row.
+type record struct {
+	// filePath is the record file the record was declared in. Templates and
+	// content files are resolved relative to its directory.
+	filePath string
+	// source identifies the record in messages, e.g. "assets/aaa/index.ini"
+	// or "assets/books/books.csv:3".
+	source string
+	// path is the Logseq page path, e.g. "xxx/yyy/aaa".
+	path   string
+	header recordHeader
+	props  *propertySet
+	// body is the content carried by the record file itself, if any.
+	body string
+	// blocks are added below the page content.
+	blocks []recordBlock
+	// hash is the hash of a row, and empty for other records.
+	hash string
+	// unchanged is true for a row the previous build generated from the same
+	// hash; it was not validated again.
+	unchanged bool
 }
 
-// processIniFile processes a single index.ini file to generate a page.
-func (g *Generator) processIniFile(iniPath string) {
-	fmt.Printf("Processing: %s\n", iniPath)
-	cfg, err := ini.Load(iniPath)
-	if err != nil {
-		log.Printf("[SKIP] Could not load %s: %v", iniPath, err)
-		return
+// loadRecords loads and validates every record file and the rows they
+// reference. Records that fail are logged and left out; the number of
+// failures is returned alongside. A markdown file that another record uses
+// as its content is not a record of its own.
+func (g *Generator) loadRecords(recordFiles []string) ([]*record, int) {
+	var records []*record
+	failed := 0
+	sources := make(map[string]string)
+	add := func(rec *record) {
+		if other, ok := sources[rec.path]; ok {
+			log.Printf("[SKIP] Page %s from %s is already generated from %s", rec.path, rec.source, other)
+			failed++
+			return
+		}
+		sources[rec.path] = rec.source
+		records = append(records, rec)
 	}
 
-	var outputContent strings.Builder
+	files := make([]*recordFile, len(recordFiles))
+	loadErrors := make([]error, len(recordFiles))
+	contentFiles := make(map[string]bool)
+	for i, filePath := range recordFiles {
+		files[i], loadErrors[i] = loadRecordFile(filePath)
+		if loadErrors[i] == nil && files[i].header.has("content") {
+			contentFiles[filepath.Join(filepath.Dir(filePath), filepath.FromSlash(files[i].header.get("content")))] = true
+		}
+	}
 
-	if shouldSkip := g.processFile(iniPath, cfg, &outputContent); shouldSkip {
-		return
+	for i, filePath := range recordFiles {
+		if filepath.Ext(filePath) == markdownExt && contentFiles[filePath] {
+			continue
+		}
+		fmt.Printf("Processing: %s\n", filePath)
+		file, err := files[i], loadErrors[i]
+		if err != nil {
+			log.Printf("[SKIP] Could not load %s: %v", filePath, err)
+			failed++
+			continue
+		}
+		if hasRows(file.header) {
+			rows, rowFailures := g.loadRows(filePath, file)
+			for _, rec := range rows {
+				add(rec)
+			}
+			failed += rowFailures
+			continue
+		}
+		rec, ok := g.loadRecord(filePath, file)
+		if !ok {
+			failed++
+			continue
+		}
+		add(rec)
 	}
+	return records, failed
+}
 
-	relPath, err := filepath.Rel(g.config.AssetsDir, filepath.Dir(iniPath))
+// loadRecord loads and validates a single record file.
+func (g *Generator) loadRecord(filePath string, file *recordFile) (*record, bool) {
+	currentPath, err := g.recordPath(filePath)
 	if err != nil {
-		log.Printf("[SKIP] Could not determine relative path for %s: %v", iniPath, err)
-		return
+		log.Printf("[SKIP] Could not determine relative path for %s: %v", filePath, err)
+		return nil, false
 	}
-
-
-	outputFilenameBase := strings.ReplaceAll(relPath, string(os.PathSeparator), "___")
-	if outputFilenameBase == "." {
-		outputFilenameBase = "index"
+	props, ok := g.loadProperties(filePath, currentPath, file.header, file.keys, file.properties, file.values)
+	if !ok {
+		return nil, false
 	}
-	outputFilepath := filepath.Join(g.config.PagesDir, fmt.Sprintf("%s.md", outputFilenameBase))
+	return &record{filePath: filePath, source: filePath, path: currentPath, header: file.header, props: props, body: file.body, blocks: file.blocks}, true
+}
 
-	finalContent := generatedMarker + "\n" + outputContent.String()
-	if err := os.WriteFile(outputFilepath, []byte(finalContent), 0644); err != nil {
-		log.Printf("[SKIP] Could not write file %s: %v", outputFilepath, err)
+// writeRecord renders a record and writes it to the pages directory.
+// The page of an unchanged row is kept without rendering it, unless it is
+// rendered with a template and the other pages have changed.
+func (g *Generator) writeRecord(rec *record) {
+	outputFilepath := g.recordFilepath(rec.path)
+	if rec.unchanged && (g.manifest.Site == g.rows.Site || !g.rendersTemplate(rec)) {
+		fmt.Printf("-> Unchanged %s\n", outputFilepath)
+		g.keepPage(outputFilepath)
+		g.addRow(rec)
 		return
 	}
-	fmt.Printf("-> Generated %s\n", outputFilepath)
-}
 
-func (g *Generator) processWithTemplate(iniPath string, cfg *ini.File, templateName string, props map[string]string, outputContent *strings.Builder) {
-	// Then, process the template
-	tmpl, err := g.getTemplate(templateName)
-	if err != nil {
-		log.Printf("[SKIP] Could not get template %s: %v", templateName, err)
+	var outputContent strings.Builder
+	if shouldSkip := g.renderRecord(rec, &outputContent); shouldSkip {
 		return
 	}
 
-	relPath, err := filepath.Rel(g.config.AssetsDir, filepath.Dir(iniPath))
-	if err != nil {
-		log.Printf("[SKIP] Could not get relative path for %s: %v", iniPath, err)
+	if err := g.writeGeneratedPage(outputFilepath, outputContent.String()); err != nil {
+		log.Printf("[SKIP] Could not write file %s: %v", outputFilepath, err)
 		return
 	}
+	g.addRow(rec)
+}
 
-	currentPath := strings.ReplaceAll(relPath, string(os.PathSeparator), "/")
+// templateData is the data passed to page templates.
+type templateData struct {
+	CurrentPath string
+	// Keys lists the page property names in output order. Properties whose
+	// value spans several lines are not page properties and are left out.
+	Keys []string
+	// Properties holds the rendered Logseq value of each property, e.g. "[[2025-09-21]]".
+	Properties map[string]string
+	// Raw holds the input value of each property, e.g. "2025-09-21".
+	Raw map[string]string
+	// Values holds the typed value of each property: float64, bool,
+	// time.Time, []schema.EnumValue or string.
+	Values map[string]interface{}
+
+	// Parent is the page of the enclosing folder, or nil.
+	Parent *Page
+	// Children are the pages of the folders directly inside this one.
+	Children []*Page
+	// Siblings are the other pages in the same folder.
+	Siblings []*Page
+	// Root is the outermost page above this one, or the page itself.
+	Root *Page
+	// Pages lists every page of the build.
+	Pages []*Page
+
+	// Content is the body of a markdown record, and empty for other records.
+	Content string
+}
 
-	data := struct {
-		CurrentPath string
-		Properties  map[string]string
-	}{
-		CurrentPath: currentPath,
-		Properties:  props,
-	}
+// processWithTemplate executes tmpl for a record and appends the result to
+// outputContent. Nothing is written if the template fails.
+func (g *Generator) processWithTemplate(rec *record, tmpl *template.Template, outputContent *strings.Builder) error {
+	data := rec.props.templateData(rec.path)
+	data.Content = rec.body
+	g.site.navigation(&data)
 
 	var renderedTemplate bytes.Buffer
 	if err := tmpl.Execute(&renderedTemplate, data); err != nil {
-		log.Printf("[SKIP] Could not execute template for %s: %v", iniPath, err)
-		return
+		return err
 	}
 	outputContent.WriteString(renderedTemplate.String())
+	return nil
 }
 
-func (g *Generator) processFile(iniPath string, cfg *ini.File, outputContent *strings.Builder) (shouldSkip bool) {
-	propertiesSection := cfg.Section("properties")
-	orderedKeys := propertiesSection.KeyStrings()
-	props := make(map[string]string)
-	for _, key := range orderedKeys {
-		props[key] = propertiesSection.Key(key).String()
+// renderRecord writes the page content of a record to outputContent,
+// followed by the record's blocks.
+func (g *Generator) renderRecord(rec *record, outputContent *strings.Builder) (shouldSkip bool) {
+	outputContent.WriteString(rec.props.Header())
+	if shouldSkip := g.renderBody(rec, outputContent); shouldSkip {
+		return true
 	}
-
-	headerSection := cfg.Section("header")
-
-	if headerSection.HasKey("schema") {
-		schemaName := headerSection.Key("schema").String()
-		s, err := g.getSchema(schemaName)
-		if err != nil {
-			log.Printf("[SKIP] Schema '%s' not found or invalid: %v", schemaName, err)
-			return true
-		}
-
-		transformedProps, err := s.ValidateAndTransform(props)
-		if err != nil {
-			log.Printf("[SKIP] Validation failed for %s: %v", iniPath, err)
-			return true
-		}
-		props = transformedProps
-	}
-
-	for _, key := range orderedKeys {
-		if value, ok := props[key]; ok {
-			outputContent.WriteString(fmt.Sprintf("%s:: %s\n", key, value))
-			delete(props, key) // Remove the key to handle remaining new properties
+	if len(rec.blocks) > 0 {
+		if content := outputContent.String(); !strings.HasSuffix(content, "\n") {
+			outputContent.WriteString("\n")
 		}
+		renderBlocks(rec, outputContent)
 	}
+	return false
+}
 
-	// Append any new properties added by the schema (e.g., defaults)
-	for key, value := range props {
-		outputContent.WriteString(fmt.Sprintf("%s:: %s\n", key, value))
-	}
-	outputContent.WriteString("\n")
-
-	if headerSection.HasKey("template") {
-		templateName := headerSection.Key("template").String()
-		g.processWithTemplate(iniPath, cfg, templateName, props, outputContent)
-	} else if headerSection.HasKey("content") {
-		contentFilename := strings.Trim(headerSection.Key("content").String(), "\"")
-		contentFilepath := filepath.Join(filepath.Dir(iniPath), contentFilename)
+// renderBody writes the body of a page: the record's template, content file
+// or markdown body, whichever comes first.
+func (g *Generator) renderBody(rec *record, outputContent *strings.Builder) (shouldSkip bool) {
+	headerSection := rec.header
+
+	if tmpl, found, err := g.recordTemplate(rec.filePath, headerSection); err != nil {
+		log.Printf("[SKIP] Could not get template for %s: %v", rec.source, err)
+		return true
+	} else if found {
+		return g.executeTemplate(rec, tmpl, outputContent)
+	} else if headerSection.has("content") {
+		contentFilename := headerSection.get("content")
+		contentFilepath := filepath.Join(filepath.Dir(rec.filePath), contentFilename)
 		if _, err := os.Stat(contentFilepath); os.IsNotExist(err) {
 			log.Printf("[SKIP] Content file '%s' not found.", contentFilepath)
 			return true
 		}
+		if renderContent(headerSection, contentFilename) {
+			tmpl, err := g.getLocalTemplate(contentFilepath)
+			if err != nil {
+				log.Printf("[SKIP] Could not get template for %s: %v", rec.source, err)
+				return true
+			}
+			return g.executeTemplate(rec, tmpl, outputContent)
+		}
 		content, err := os.ReadFile(contentFilepath)
 		if err != nil {
 			log.Printf("[SKIP] Could not read content file %s: %v", contentFilepath, err)
 			return true
 		}
 		outputContent.Write(content)
+	} else if rec.body != "" {
+		if !renderContent(headerSection, "") {
+			outputContent.WriteString(rec.body)
+			return false
+		}
+		tmpl, err := g.getInlineTemplate(rec.path, rec.body)
+		if err != nil {
+			log.Printf("[SKIP] Could not get template for %s: %v", rec.source, err)
+			return true
+		}
+		return g.executeTemplate(rec, tmpl, outputContent)
+	}
+	return false
+}
+
+// executeTemplate renders the body of a record with tmpl, logging a failure
+// and reporting it by returning true.
+func (g *Generator) executeTemplate(rec *record, tmpl *template.Template, outputContent *strings.Builder) (shouldSkip bool) {
+	if err := g.processWithTemplate(rec, tmpl, outputContent); err != nil {
+		log.Printf("[SKIP] Could not execute template for %s: %v", rec.source, err)
+		return true
 	}
 	return false
 }
 
-// getTemplate retrieves a template from cache or parses it from file.
-func (g *Generator) getTemplate(name string) (*template.Template, error) {
-	if tmpl, ok := g.templateCache[name]; ok {
-		return tmpl, nil
+// recordTemplate returns the template a record is rendered with, if any.
+// The header's template key names a template in the template directory, or,
+// if it ends in ".template", a file relative to the record's directory.
+// Without a template or content key, an index.template next to the record
+// is used if present, except for markdown records, which carry their own
+// content.
+func (g *Generator) recordTemplate(filePath string, headerSection recordHeader) (*template.Template, bool, error) {
+	if headerSection.has("template") {
+		tmpl, err := g.namedTemplate(filePath, headerSection.get("template"))
+		return tmpl, err == nil, err
 	}
+	if headerSection.has("content") || filepath.Ext(filePath) == markdownExt {
+		return nil, false, nil
+	}
+	localPath := filepath.Join(filepath.Dir(filePath), "index"+templateExt)
+	if _, err := os.Stat(localPath); err != nil {
+		return nil, false, nil
+	}
+	tmpl, err := g.getLocalTemplate(localPath)
+	return tmpl, err == nil, err
+}
 
-	templateFile := filepath.Join(g.config.TemplateDir, fmt.Sprintf("%s.template", name))
-	content, err := os.ReadFile(templateFile)
+// namedTemplate returns the template named by a record header: a template in
+// the template directory, or, if the name ends in ".template", a file
+// relative to the record's directory.
+func (g *Generator) namedTemplate(filePath, templateName string) (*template.Template, error) {
+	if filepath.Ext(templateName) != templateExt {
+		return g.getTemplate(templateName)
+	}
+	return g.getLocalTemplate(filepath.Join(filepath.Dir(filePath), filepath.FromSlash(templateName)))
+}
+
+// renderContent reports whether a content file is rendered as a template
+// rather than copied. The header's content_render key decides if present;
+// otherwise files with the .md.tmpl extension are rendered.
+func renderContent(headerSection recordHeader, contentFilename string) bool {
+	if headerSection.has("content_render") {
+		return headerSection.bool("content_render")
+	}
+	return strings.HasSuffix(contentFilename, ".md.tmpl")
+}
+
+// loadProperties takes the properties of a record and, if the record
+// references a schema, migrates, computes, validates and transforms them.
+// decoded holds the values of formats with types of their own, which are
+// checked against the schema's types. Failures are logged and reported by
+// returning false.
+func (g *Generator) loadProperties(source, currentPath string, headerSection recordHeader, orderedKeys []string, props map[string]string, decoded map[string]interface{}) (*propertySet, bool) {
+	if !headerSection.has("schema") {
+		values := make(map[string]interface{}, len(props))
+		for key, value := range props {
+			values[key] = value
+		}
+		return newPropertySet(orderedKeys, props, props, values), true
+	}
+
+	schemaName := headerSection.get("schema")
+	s, err := g.getSchema(schemaName)
 	if err != nil {
-		return nil, fmt.Errorf("could not read template file %s: %w", templateFile, err)
+		log.Printf("[SKIP] Schema '%s' not found or invalid: %v", schemaName, err)
+		if g.brokenSchemas[schemaName] {
+			// The record is not at fault; its page stays until the schema
+			// is fixed.
+			g.keepPage(g.recordFilepath(currentPath))
+		}
+		return nil, false
 	}
 
-	tmpl, err := template.New(name).Parse(string(content))
+	version, err := recordVersion(headerSection)
+	if err != nil {
+		log.Printf("[SKIP] Invalid schema_version in %s: %v", source, err)
+		return nil, false
+	}
+	original := props
+	orderedKeys, props, err = s.Migrate(orderedKeys, props, version)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse template %s: %w", name, err)
+		log.Printf("[SKIP] Migration failed for %s: %v", source, err)
+		return nil, false
 	}
 
-	g.templateCache[name] = tmpl
-	return tmpl, nil
-}
+	props, err = s.Compute(props, schema.Env{CurrentPath: currentPath})
+	if err != nil {
+		log.Printf("[SKIP] Validation failed for %s: %v", source, err)
+		return nil, false
+	}
 
-// getSchema retrieves a schema from cache or loads it from file.
-func (g *Generator) getSchema(name string) (*schema.Schema, error) {
-	if s, ok := g.schemaCache[name]; ok {
-		return s, nil
+	// Values that migrations changed are only known as strings.
+	typed := make(map[string]interface{}, len(decoded))
+	for key, value := range decoded {
+		if current, ok := props[key]; ok && current == original[key] {
+			typed[key] = value
+		}
+	}
+	transformedProps, err := s.ValidateAndTransformDecoded(props, typed)
+	if err != nil {
+		log.Printf("[SKIP] Validation failed for %s: %v", source, err)
+		return nil, false
+	}
+	if err := g.validation.CheckUnique(s, source, props); err != nil {
+		log.Printf("[SKIP] Validation failed for %s: %v", source, err)
+		return nil, false
+	}
+	for _, warning := range s.Warnings(props) {
+		log.Printf("[WARN] %s: %s", source, warning)
 	}
 
-	schemaFile := filepath.Join(g.config.SchemaDir, fmt.Sprintf("%s.yaml", name))
-	if _, err := os.Stat(schemaFile); os.IsNotExist(err) {
-		schemaFile = filepath.Join(g.config.SchemaDir, fmt.Sprintf("%s.json", name))
+	return newPropertySet(orderedKeys, s.WithDefaults(props), transformedProps, s.TypedValues(props)), true
+}
+
+// currentPath returns the Logseq page path of the record file at filePath, e.g. "xxx/yyy/aaa".
+func (g *Generator) currentPath(filePath string) (string, error) {
+	relPath, err := filepath.Rel(g.config.AssetsDir, filepath.Dir(filePath))
+	if err != nil {
+		return "", err
 	}
+	return strings.ReplaceAll(relPath, string(os.PathSeparator), "/"), nil
+}
 
-	s, err := schema.LoadSchema(schemaFile)
+// recordPath returns the Logseq page path of the record in the file at
+// filePath. Records are named after their folder, except markdown records,
+// which are named after the file unless it is called index.md.
+func (g *Generator) recordPath(filePath string) (string, error) {
+	currentPath, err := g.currentPath(filePath)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	name := filepath.Base(filePath)
+	if filepath.Ext(name) != markdownExt || name == "index"+markdownExt {
+		return currentPath, nil
+	}
+	return path.Join(currentPath, strings.TrimSuffix(name, markdownExt)), nil
+}
 
-	g.schemaCache[name] = s
-	return s, nil
+// recordVersion returns the schema version a record declares in its header,
+// or 0 if it does not declare one.
+func recordVersion(headerSection recordHeader) (int, error) {
+	if !headerSection.has("schema_version") {
+		return 0, nil
+	}
+	return strconv.Atoi(headerSection.get("schema_version"))
+}
+
+// getSchema retrieves a resolved schema from the registry.
+func (g *Generator) getSchema(name string) (*schema.Schema, error) {
+	if g.brokenSchemas[name] {
+		return nil, fmt.Errorf("schema '%s' has lint errors", name)
+	}
+	return g.schemas.Get(name)
 }
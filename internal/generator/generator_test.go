@@ -179,3 +179,120 @@ func TestGenerator_Build_Order(t *testing.T) {
 
 	require.Equal(t, expectedContent, string(outputContent))
 }
+
+func TestGenerator_Build_Unique(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  id:\n" +
+		"    type: string\n" +
+		"    unique: true\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"asset.yaml": schemaContent})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"first/index.ini":  "[header]\nschema = asset\n[properties]\nid = A-1\n",
+		"second/index.ini": "[header]\nschema = asset\n[properties]\nid = A-1\n",
+		"third/index.ini":  "[header]\nschema = asset\n[properties]\nid = A-2\n",
+	})
+
+	gen := generator.New(cfg)
+	require.NoError(t, gen.Build())
+
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "first.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "second.md"))
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "third.md"))
+
+	// Rebuilding starts from a fresh validation context.
+	require.NoError(t, gen.Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "first.md"))
+}
+
+func TestGenerator_Build_Computed(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  title:\n" +
+		"    type: string\n" +
+		"  slug:\n" +
+		"    type: string\n" +
+		"    compute: '{{ slug .Properties.title }}'\n" +
+		"  parent:\n" +
+		"    type: string\n" +
+		"    compute: '{{ .CurrentPath }}'\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"note.yaml": schemaContent})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"notes/first/index.ini": "[header]\nschema = note\n[properties]\ntitle = My First Note\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "notes___first.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "slug:: my-first-note\n")
+	assert.Contains(t, string(content), "parent:: notes/first\n")
+}
+
+func TestGenerator_Build_TypedTemplateValues(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  pages:\n" +
+		"    type: number\n" +
+		"  read:\n" +
+		"    type: boolean\n" +
+		"    default: false\n" +
+		"  date:\n" +
+		"    type: date\n" +
+		"  genre:\n" +
+		"    type: enum\n" +
+		"    keys:\n" +
+		"      scifi:\n" +
+		"        display: Science Fiction\n" +
+		"      drama: {}\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": schemaContent})
+
+	templateContent := "{{ if gt .Values.pages 300.0 }}long{{ else }}short{{ end }}\n" +
+		"{{ if not .Values.read }}unread{{ end }}\n" +
+		"{{ .Values.date.Format \"Jan 2006\" }} ({{ .Raw.date }})\n" +
+		"{{ range .Values.genre }}{{ .Key }}={{ .Display }};{{ end }}\n"
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": templateContent})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"dune/index.ini": "[header]\nschema = book\ntemplate = book\n[properties]\npages = 412\ndate = 1965-08-01\ngenre = scifi, drama\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "dune.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "date:: [[1965-08-01]]\n")
+	assert.Contains(t, string(content), "\nlong\nunread\nAug 1965 (1965-08-01)\nscifi=Science Fiction;drama=drama;\n")
+}
+
+// newTestConfig returns a configuration whose directories live in a fresh
+// temporary directory. Only the assets directory exists; the others are
+// created as files are written.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		AssetsDir:   filepath.Join(tempDir, "assets"),
+		PagesDir:    filepath.Join(tempDir, "pages"),
+		SchemaDir:   filepath.Join(tempDir, "schemas"),
+		TemplateDir: filepath.Join(tempDir, "templates"),
+	}
+	require.NoError(t, os.MkdirAll(cfg.AssetsDir, 0755))
+	return cfg
+}
+
+// writeFiles writes files below dir, keyed by their slash-separated path
+// relative to it.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// journalFileFormat is the layout of Logseq's journal file names.
+const journalFileFormat = "2006_01_02"
+
+// journalRegion is the first line of the block that holds the generated
+// blocks of a journal. The block has content, so that Logseq does not read
+// it as page properties when it comes first, and is marked by the property
+// on its second line. The generator owns this block and everything indented
+// below it; the rest of the journal is hand-written and left alone.
+const journalRegion = "- Generated"
+
+// journalRegionProperty is the line below journalRegion that marks it.
+const journalRegionProperty = "  " + generatedMarker
+
+// legacyJournalRegion is the first line of regions written by earlier
+// versions, which held only the property.
+const legacyJournalRegion = "- " + generatedMarker
+
+// defaultJournalTemplate links to the record's page.
+const defaultJournalTemplate = "- {{ pageRef .CurrentPath }}\n"
+
+// generateJournals writes the journal blocks of every record whose header
+// has a journal key into the generated region of the journal for that date,
+// if a journals directory is configured. Regions left by the previous build
+// whose records are gone are removed.
+func (g *Generator) generateJournals(records []*record) {
+	if g.config.JournalsDir == "" {
+		return
+	}
+
+	blocks := make(map[string]*strings.Builder)
+	for _, rec := range records {
+		if !rec.header.has("journal") {
+			continue
+		}
+		date, err := journalDate(rec)
+		if err != nil {
+			log.Printf("[SKIP] Could not add %s to a journal: %v", rec.source, err)
+			continue
+		}
+		tmpl, err := g.journalTemplate(rec)
+		if err != nil {
+			log.Printf("[SKIP] Could not get journal template for %s: %v", rec.source, err)
+			continue
+		}
+		journalFilepath := filepath.Join(g.config.JournalsDir, date.Format(journalFileFormat)+".md")
+		if blocks[journalFilepath] == nil {
+			blocks[journalFilepath] = &strings.Builder{}
+		}
+		if err := g.processWithTemplate(rec, tmpl, blocks[journalFilepath]); err != nil {
+			log.Printf("[SKIP] Could not execute journal template for %s: %v", rec.source, err)
+		}
+	}
+
+	journals, err := filepath.Glob(filepath.Join(g.config.JournalsDir, "*.md"))
+	if err != nil {
+		log.Printf("Error finding journals: %v", err)
+	}
+	for _, journal := range journals {
+		if blocks[journal] == nil {
+			blocks[journal] = &strings.Builder{}
+		}
+	}
+	paths := make([]string, 0, len(blocks))
+	for journal := range blocks {
+		paths = append(paths, journal)
+	}
+	sort.Strings(paths)
+
+	if err := os.MkdirAll(g.config.JournalsDir, 0755); err != nil {
+		log.Printf("[SKIP] Could not create journals directory: %v", err)
+		return
+	}
+	for _, journal := range paths {
+		if err := updateJournal(journal, blocks[journal].String()); err != nil {
+			log.Printf("[SKIP] Could not update journal %s: %v", journal, err)
+		}
+	}
+}
+
+// journalDate returns the journal date of a record. The header's journal key
+// holds either a YYYY-MM-DD date or the name of a date property.
+func journalDate(rec *record) (time.Time, error) {
+	value := rec.header.get("journal")
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	if date, ok := rec.props.Values()[value].(time.Time); ok {
+		return date, nil
+	}
+	if raw, ok := rec.props.Raw()[value]; ok {
+		if date, err := time.Parse("2006-01-02", raw); err == nil {
+			return date, nil
+		}
+		return time.Time{}, fmt.Errorf("property '%s' is not a date in YYYY-MM-DD format", value)
+	}
+	return time.Time{}, fmt.Errorf("journal '%s' is neither a date nor a property", value)
+}
+
+// journalTemplate returns the template of a record's journal blocks: the
+// header's journal_template, named like template, or a link to the page.
+func (g *Generator) journalTemplate(rec *record) (*template.Template, error) {
+	if !rec.header.has("journal_template") {
+		return g.getInlineTemplate("journal", defaultJournalTemplate)
+	}
+	return g.namedTemplate(rec.filePath, rec.header.get("journal_template"))
+}
+
+// updateJournal replaces the generated region of a journal with blocks,
+// indented below the region's first line. The region stays where it was, or
+// is appended to the journal. A journal left empty is removed.
+func updateJournal(journalFilepath, blocks string) error {
+	existing, err := os.ReadFile(journalFilepath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	before, after, found := splitJournal(string(existing))
+	if !found && blocks == "" {
+		return nil
+	}
+	if !found && before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+
+	var region strings.Builder
+	if blocks != "" {
+		region.WriteString(journalRegion + "\n" + journalRegionProperty + "\n")
+		// Blank lines are indented too, so that the region stays one
+		// indented run of lines.
+		for _, line := range strings.Split(strings.TrimRight(blocks, "\n"), "\n") {
+			region.WriteString("\t" + line + "\n")
+		}
+	}
+	content := before + region.String() + after
+
+	if content == string(existing) {
+		return nil
+	}
+	if strings.TrimSpace(content) == "" {
+		if err := os.Remove(journalFilepath); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", filepath.Base(journalFilepath))
+		return nil
+	}
+	if err := os.WriteFile(journalFilepath, []byte(content), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("-> Updated %s\n", journalFilepath)
+	return nil
+}
+
+// splitJournal returns the content of a journal before and after its
+// generated region, and whether it has one. Without a region, everything is
+// before it. The region ends at the first line that is not indented; blank
+// lines belong to it only if an indented line follows them.
+func splitJournal(content string) (before, after string, found bool) {
+	lines := strings.SplitAfter(content, "\n")
+	for i := range lines {
+		if !isJournalRegion(lines[i:]) {
+			continue
+		}
+		end := i + 1
+		for next := end; next < len(lines); next++ {
+			if isIndented(lines[next]) {
+				end = next + 1
+			} else if strings.TrimSpace(lines[next]) != "" {
+				break
+			}
+		}
+		return strings.Join(lines[:i], ""), strings.Join(lines[end:], ""), true
+	}
+	return content, "", false
+}
+
+// isJournalRegion reports whether lines start with the first line of a
+// generated region.
+func isJournalRegion(lines []string) bool {
+	switch strings.TrimRight(lines[0], "\r\n") {
+	case legacyJournalRegion:
+		return true
+	case journalRegion:
+		// A hand-written block of the same name lacks the property.
+		return len(lines) > 1 && strings.TrimSpace(lines[1]) == generatedMarker
+	}
+	return false
+}
+
+// isIndented reports whether a journal line is indented below the block
+// above it.
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ")
+}
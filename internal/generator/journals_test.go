@@ -0,0 +1,103 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_Journals(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.JournalsDir = filepath.Join(filepath.Dir(cfg.AssetsDir), "journals")
+	writeFiles(t, cfg.TemplateDir, map[string]string{"due.template": "- Return {{ pageRef .CurrentPath }}\n  shelf:: {{ .Raw.shelf }}\n"})
+
+	files := map[string]string{
+		"meeting/index.ini": "[header]\njournal = 2025-09-21\n",
+		"books/index.ini":   "[header]\nrows = books.csv\njournal = due\njournal_template = due\n",
+		"books/books.csv":   "id,due,shelf\ndune,2025-09-21,A\nemma,2025-10-01,B\n",
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	// Hand-written journals, two of them with a region left by an earlier
+	// version, which marked it with a property block.
+	writeFiles(t, cfg.JournalsDir, map[string]string{
+		"2025_09_21.md": "- Morning notes\n\t- coffee\n- Generated\n\t- by hand\n",
+		"2025_09_01.md": "- Before\n- generated:: true\n\t- [[old]]\n- After\n",
+		"2025_09_02.md": "- generated:: true\n\t- [[old]]\n",
+	})
+
+	gen := generator.New(cfg)
+	require.NoError(t, gen.Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.JournalsDir, "2025_09_21.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "- Morning notes\n\t- coffee\n- Generated\n\t- by hand\n"+
+		"- Generated\n  generated:: true\n"+
+		"\t- Return [[books/dune]]\n"+
+		"\t  shelf:: A\n"+
+		"\t- [[meeting]]\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.JournalsDir, "2025_10_01.md"))
+	require.NoError(t, err)
+	// The region starts with a block of its own, so a new journal does not
+	// get the marker as page properties.
+	assert.Equal(t, "- Generated\n  generated:: true\n\t- Return [[books/emma]]\n\t  shelf:: B\n", string(content))
+
+	// Stale regions are removed, and so are journals left empty.
+	content, err = os.ReadFile(filepath.Join(cfg.JournalsDir, "2025_09_01.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "- Before\n- After\n", string(content))
+	assert.NoFileExists(t, filepath.Join(cfg.JournalsDir, "2025_09_02.md"))
+
+	// Hand-written additions survive a rebuild, and the region is rewritten in place.
+	journal := filepath.Join(cfg.JournalsDir, "2025_10_01.md")
+	content, err = os.ReadFile(journal)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(journal, []byte("- Top\n"+string(content)+"- Bottom\n"), 0644))
+	require.NoError(t, generator.New(cfg).Build())
+	content, err = os.ReadFile(journal)
+	require.NoError(t, err)
+	assert.Equal(t, "- Top\n- Generated\n  generated:: true\n\t- Return [[books/emma]]\n\t  shelf:: B\n- Bottom\n", string(content))
+
+	// Journals are never cleared as generated pages.
+	require.NoError(t, generator.New(cfg).Clear())
+	assert.FileExists(t, journal)
+}
+
+func TestGenerator_Build_JournalsRebuild(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.JournalsDir = filepath.Join(filepath.Dir(cfg.AssetsDir), "journals")
+	writeFiles(t, cfg.TemplateDir, map[string]string{"two.template": "- one\n\n- two\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{"event/index.ini": "[header]\njournal = 2025-09-21\njournal_template = two\n"})
+	writeFiles(t, cfg.JournalsDir, map[string]string{"2025_09_21.md": "- Notes\n"})
+
+	expected := "- Notes\n- Generated\n  generated:: true\n\t- one\n\t\n\t- two\n"
+	journal := filepath.Join(cfg.JournalsDir, "2025_09_21.md")
+	for i := 0; i < 2; i++ {
+		require.NoError(t, generator.New(cfg).Build())
+		content, err := os.ReadFile(journal)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), "build %d", i+1)
+	}
+
+	// Regions written with unindented blank lines are still replaced whole.
+	require.NoError(t, os.WriteFile(journal, []byte("- Notes\n- generated:: true\n\t- one\n\n\t- two\n\n- After\n"), 0644))
+	require.NoError(t, generator.New(cfg).Build())
+	content, err := os.ReadFile(journal)
+	require.NoError(t, err)
+	assert.Equal(t, expected+"\n- After\n", string(content))
+}
+
+func TestGenerator_Check_Journals(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.JournalsDir = filepath.Join(filepath.Dir(cfg.AssetsDir), "journals")
+	writeFiles(t, cfg.AssetsDir, map[string]string{"event/index.ini": "[header]\njournal = when\n[properties]\nwhen = soon\n"})
+
+	err := generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 invalid record(s)")
+}
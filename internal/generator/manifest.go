@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile is the file in the pages directory that records the rows of
+// the previous build, so that unchanged rows are neither validated nor
+// rendered again.
+const manifestFile = ".logseq_gen_rows.json"
+
+// rowManifest describes the pages generated from rows by a build.
+type rowManifest struct {
+	// Site is the hash of the page tree the templates of the build navigated.
+	Site string `json:"site"`
+	// Rows is keyed by page file name.
+	Rows map[string]rowEntry `json:"rows"`
+}
+
+// rowEntry is a validated row. Hash covers everything its page was generated
+// from except the other pages.
+type rowEntry struct {
+	Hash        string            `json:"hash"`
+	Keys        []string          `json:"keys"`
+	Raw         map[string]string `json:"raw"`
+	Transformed map[string]string `json:"transformed"`
+}
+
+// loadManifest reads the manifest of the previous build. A missing or
+// unreadable manifest means that every row is validated and rendered.
+func (g *Generator) loadManifest() rowManifest {
+	manifest := rowManifest{Rows: map[string]rowEntry{}}
+	data, err := os.ReadFile(filepath.Join(g.config.PagesDir, manifestFile))
+	if err != nil {
+		return manifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		log.Printf("Ignoring %s: %v", manifestFile, err)
+		return rowManifest{Rows: map[string]rowEntry{}}
+	}
+	if manifest.Rows == nil {
+		manifest.Rows = map[string]rowEntry{}
+	}
+	return manifest
+}
+
+// saveManifest writes the rows of the current build, or removes the
+// manifest if the build has none.
+func (g *Generator) saveManifest() error {
+	manifestPath := filepath.Join(g.config.PagesDir, manifestFile)
+	if len(g.rows.Rows) == 0 {
+		if err := os.Remove(manifestPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(g.rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, append(data, '\n'), 0644)
+}
+
+// rowInputs returns the hash of the files every row of a record file depends
+// on: the schemas, the templates, and the files next to the record file,
+// except the rows themselves.
+func (g *Generator) rowInputs(filePath string, header recordHeader) (string, error) {
+	if g.sharedInputs == "" {
+		h := sha256.New()
+		for _, dir := range []string{g.config.SchemaDir, g.config.TemplateDir} {
+			if err := hashTree(h, dir); err != nil {
+				return "", err
+			}
+		}
+		g.sharedInputs = hex.EncodeToString(h.Sum(nil))
+	}
+
+	rowsName := header.get("rows")
+	if header.has("sqlite") {
+		rowsName = header.get("sqlite")
+	}
+	// An SQLite database may have journal files next to it.
+	rowsName = filepath.Base(filepath.FromSlash(rowsName))
+
+	h := sha256.New()
+	h.Write([]byte(g.sharedInputs))
+	dir := filepath.Dir(filePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), rowsName) {
+			continue
+		}
+		if err := hashFile(h, filepath.Join(dir, entry.Name()), entry.Name()); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTree adds every file below dir to h. A missing directory adds nothing.
+func hashTree(h hash.Hash, dir string) error {
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		return hashFile(h, filePath, filepath.ToSlash(name))
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// hashFile adds the name and content of a file to h.
+func hashFile(h hash.Hash, filePath, name string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+	h.Write(data)
+	return nil
+}
+
+// rowHash returns the hash of a row: its properties, in order, and the
+// inputs of its record file.
+func rowHash(inputs string, orderedKeys []string, props map[string]string) string {
+	data, _ := json.Marshal(struct {
+		Inputs string
+		Keys   []string
+		Props  map[string]string
+	}{inputs, orderedKeys, props})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// unchangedRow returns the properties of a row that the previous build
+// validated with the same hash, if its page is still there. The row's unique
+// values are claimed as if it had been validated again.
+func (g *Generator) unchangedRow(source, currentPath, rowHash string, header recordHeader) (*propertySet, bool) {
+	outputFilepath := g.recordFilepath(currentPath)
+	entry, ok := g.manifest.Rows[filepath.Base(outputFilepath)]
+	if !ok || entry.Hash != rowHash || !g.previous[outputFilepath] {
+		return nil, false
+	}
+
+	values := make(map[string]interface{}, len(entry.Raw))
+	if header.has("schema") {
+		s, err := g.getSchema(header.get("schema"))
+		if err != nil {
+			return nil, false
+		}
+		if err := g.validation.CheckUnique(s, source, entry.Raw); err != nil {
+			return nil, false
+		}
+		values = s.TypedValues(entry.Raw)
+	} else {
+		for key, value := range entry.Raw {
+			values[key] = value
+		}
+	}
+	return newPropertySet(entry.Keys, entry.Raw, entry.Transformed, values), true
+}
+
+// addRow adds a row whose page was written or kept to the manifest of the
+// current build.
+func (g *Generator) addRow(rec *record) {
+	if rec.hash == "" {
+		return
+	}
+	g.rows.Rows[filepath.Base(g.recordFilepath(rec.path))] = rowEntry{
+		Hash:        rec.hash,
+		Keys:        rec.props.Keys(),
+		Raw:         rec.props.Raw(),
+		Transformed: rec.props.Transformed(),
+	}
+}
+
+// rendersTemplate reports whether the page of a record is rendered with a
+// template, which may navigate the other pages of the build.
+func (g *Generator) rendersTemplate(rec *record) bool {
+	if _, found, err := g.recordTemplate(rec.filePath, rec.header); err != nil || found {
+		return true
+	}
+	return rec.header.has("content") && renderContent(rec.header, rec.header.get("content"))
+}
+
+// fingerprint returns the hash of the page tree: every page with its
+// properties.
+func (s *site) fingerprint() string {
+	data, _ := json.Marshal(s.pages)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Migrate rewrites index.ini files that were written for an older version of
+// their schema, applying the schema's migrations in place. Records in other
+// formats cannot be rewritten; outdated ones are reported and fail the
+// migration.
+func (g *Generator) Migrate() error {
+	fmt.Printf("Migrating records in %s...\n", g.config.AssetsDir)
+	recordFiles, err := g.findRecordFiles()
+	if err != nil {
+		return fmt.Errorf("error finding record files: %w", err)
+	}
+
+	notMigrated := 0
+	for _, recordPath := range recordFiles {
+		if filepath.Base(recordPath) == "index.ini" {
+			g.migrateIniFile(recordPath)
+		} else if g.isOutdated(recordPath) {
+			notMigrated++
+		}
+	}
+	if notMigrated > 0 {
+		return fmt.Errorf("migration incomplete: %d record(s) outside index.ini files must be migrated by hand", notMigrated)
+	}
+	fmt.Println("Migration finished.")
+	return nil
+}
+
+// isOutdated reports whether a record in a format migrate cannot rewrite was
+// written for an older version of its schema, and logs it if so.
+func (g *Generator) isOutdated(recordPath string) bool {
+	file, err := loadRecordFile(recordPath)
+	if err != nil {
+		log.Printf("[SKIP] Could not load %s: %v", recordPath, err)
+		return false
+	}
+	if !file.header.has("schema") {
+		return false
+	}
+	schemaName := file.header.get("schema")
+	s, err := g.getSchema(schemaName)
+	if err != nil {
+		log.Printf("[SKIP] Schema '%s' not found or invalid: %v", schemaName, err)
+		return false
+	}
+	version, err := recordVersion(file.header)
+	if err != nil {
+		log.Printf("[SKIP] Invalid schema_version in %s: %v", recordPath, err)
+		return false
+	}
+	if version == 0 || version >= s.Version {
+		return false
+	}
+	log.Printf("[SKIP] %s is written for version %d of schema '%s', version %d is current; only index.ini files are rewritten", recordPath, version, s.Name, s.Version)
+	return true
+}
+
+// migrateIniFile upgrades a single index.ini file to its schema's current version.
+func (g *Generator) migrateIniFile(iniPath string) {
+	cfg, err := ini.Load(iniPath)
+	if err != nil {
+		log.Printf("[SKIP] Could not load %s: %v", iniPath, err)
+		return
+	}
+
+	headerSection := cfg.Section("header")
+	if !headerSection.HasKey("schema") {
+		return
+	}
+	schemaName := headerSection.Key("schema").String()
+	s, err := g.getSchema(schemaName)
+	if err != nil {
+		log.Printf("[SKIP] Schema '%s' not found or invalid: %v", schemaName, err)
+		return
+	}
+
+	version, err := recordVersion(iniHeader(headerSection))
+	if err != nil {
+		log.Printf("[SKIP] Invalid schema_version in %s: %v", iniPath, err)
+		return
+	}
+	if version == 0 || version >= s.Version {
+		return
+	}
+
+	propertiesSection := cfg.Section("properties")
+	orderedKeys := propertiesSection.KeyStrings()
+	props := make(map[string]string)
+	for _, key := range orderedKeys {
+		props[key] = propertiesSection.Key(key).String()
+	}
+
+	orderedKeys, props, err = s.Migrate(orderedKeys, props, version)
+	if err != nil {
+		log.Printf("[SKIP] Migration failed for %s: %v", iniPath, err)
+		return
+	}
+
+	data, err := os.ReadFile(iniPath)
+	if err != nil {
+		log.Printf("[SKIP] Could not read %s: %v", iniPath, err)
+		return
+	}
+	content, err := rewriteIniRecord(string(data), propertiesSection.KeyStrings(), orderedKeys, props, s.Version)
+	if err != nil {
+		log.Printf("[SKIP] Could not rewrite %s: %v", iniPath, err)
+		return
+	}
+	if err := os.WriteFile(iniPath, []byte(content), 0644); err != nil {
+		log.Printf("[SKIP] Could not write %s: %v", iniPath, err)
+		return
+	}
+	fmt.Printf("-> Migrated %s from version %d to %d\n", iniPath, version, s.Version)
+}
+
+// rewriteIniRecord rewrites the [properties] and schema_version of an
+// index.ini in place. Lines of properties the migration did not change are
+// kept as written, as are comments and blank lines; changed properties take
+// the place of the ones they replace, and new ones follow the last property.
+func rewriteIniRecord(data string, oldKeys, keys []string, props map[string]string, version int) (string, error) {
+	cfg, err := ini.Load([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	oldProps := cfg.Section("properties")
+
+	lines := strings.SplitAfter(data, "\n")
+	var section string
+	var propertySlots []int
+	versionLine, lastHeaderLine := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.Contains(trimmed, "\"\"\"") {
+			return "", fmt.Errorf("multi-line values are not supported")
+		}
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if section == "header" {
+				lastHeaderLine = i
+			}
+			continue
+		}
+		key, ok := iniLineKey(trimmed)
+		if !ok {
+			continue
+		}
+		switch section {
+		case "header":
+			lastHeaderLine = i
+			if key == "schema_version" {
+				versionLine = i
+			}
+		case "properties":
+			propertySlots = append(propertySlots, i)
+		}
+	}
+	if len(propertySlots) != len(oldKeys) {
+		return "", fmt.Errorf("could not match the [properties] lines")
+	}
+
+	original := make(map[string]string, len(oldKeys))
+	for i, key := range oldKeys {
+		original[key] = lines[propertySlots[i]]
+	}
+	newLines := make([]string, len(keys))
+	for i, key := range keys {
+		if line, ok := original[key]; ok && oldProps.Key(key).String() == props[key] {
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			newLines[i] = line
+		} else {
+			newLines[i] = iniLine(key, props[key])
+		}
+	}
+
+	// Fill the slots of the old properties, then append what is left after
+	// the last one; unused slots are dropped.
+	drop := make(map[int]bool)
+	for i, slot := range propertySlots {
+		if i < len(newLines) {
+			lines[slot] = newLines[i]
+		} else {
+			drop[slot] = true
+		}
+	}
+	if extra := newLines[min(len(newLines), len(propertySlots)):]; len(extra) > 0 {
+		if len(propertySlots) == 0 {
+			return "", fmt.Errorf("no [properties] to add to")
+		}
+		last := propertySlots[len(propertySlots)-1]
+		if !strings.HasSuffix(lines[last], "\n") {
+			lines[last] += "\n"
+		}
+		lines[last] += strings.Join(extra, "")
+	}
+
+	versionValue := fmt.Sprintf("%d", version)
+	if versionLine >= 0 {
+		line := lines[versionLine]
+		sep := strings.IndexAny(line, "=:")
+		rest := line[sep+1:]
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			rest = strings.Replace(rest, fields[0], versionValue, 1)
+		} else {
+			rest = " " + versionValue + rest
+		}
+		lines[versionLine] = line[:sep+1] + rest
+	} else if lastHeaderLine >= 0 {
+		lines[lastHeaderLine] = strings.TrimRight(lines[lastHeaderLine], "\n") + "\n" + iniLine("schema_version", versionValue)
+	} else {
+		return "", fmt.Errorf("no [header] to set schema_version in")
+	}
+
+	var result strings.Builder
+	for i, line := range lines {
+		if !drop[i] {
+			result.WriteString(line)
+		}
+	}
+	return result.String(), nil
+}
+
+// iniLineKey returns the key of an ini key line.
+func iniLineKey(trimmed string) (string, bool) {
+	if trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+		return "", false
+	}
+	sep := strings.IndexAny(trimmed, "=:")
+	if sep < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[:sep]), true
+}
+
+// iniLine formats a key line, quoting values that ini would otherwise trim
+// or cut at a comment.
+func iniLine(key, value string) string {
+	if strings.ContainsAny(value, "#;\"`") || strings.TrimSpace(value) != value {
+		if strings.Contains(value, "`") {
+			value = `"""` + value + `"""`
+		} else {
+			value = "`" + value + "`"
+		}
+	}
+	return fmt.Sprintf("%s = %s\n", key, value)
+}
@@ -0,0 +1,94 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Migrate(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 2\n" +
+		"types:\n" +
+		"  color:\n" +
+		"    type: string\n" +
+		"    required: true\n" +
+		"migrations:\n" +
+		"  - from: 1\n" +
+		"    steps:\n" +
+		"      - rename: {from: colour, to: color}\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"paint.yaml": schemaContent})
+
+	iniContent := "[header]\n" +
+		"schema = paint\n" +
+		"schema_version = 1\n" +
+		"\n" +
+		"[properties]\n" +
+		"; the colour is spelled the old way\n" +
+		"name = Red\n" +
+		"colour = crimson\n" +
+		"finish= matte\n"
+	writeFiles(t, cfg.AssetsDir, map[string]string{"red/index.ini": iniContent})
+	iniPath := filepath.Join(cfg.AssetsDir, "red", "index.ini")
+
+	gen := generator.New(cfg)
+
+	t.Run("Build migrates on the fly", func(t *testing.T) {
+		require.NoError(t, gen.Build())
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "red.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "generated:: true\nname:: Red\ncolor:: crimson\nfinish:: matte\n\n", string(content))
+
+		original, err := os.ReadFile(iniPath)
+		require.NoError(t, err)
+		assert.Equal(t, iniContent, string(original))
+	})
+
+	t.Run("Migrate rewrites the record", func(t *testing.T) {
+		require.NoError(t, gen.Migrate())
+		content, err := os.ReadFile(iniPath)
+		require.NoError(t, err)
+		// Only the changed lines are rewritten.
+		assert.Equal(t, "[header]\n"+
+			"schema = paint\n"+
+			"schema_version = 2\n"+
+			"\n"+
+			"[properties]\n"+
+			"; the colour is spelled the old way\n"+
+			"name = Red\n"+
+			"color = crimson\n"+
+			"finish= matte\n", string(content))
+
+		require.NoError(t, gen.Build())
+		page, err := os.ReadFile(filepath.Join(cfg.PagesDir, "red.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "generated:: true\nname:: Red\ncolor:: crimson\nfinish:: matte\n\n", string(page))
+	})
+
+	t.Run("Migrate reports records it cannot rewrite", func(t *testing.T) {
+		yamlContent := "header:\n  schema: paint\n  schema_version: 1\nproperties:\n  colour: teal\n"
+		markdownContent := "---\nheader:\n  schema: paint\n  schema_version: 1\nproperties:\n  colour: navy\n---\n"
+		writeFiles(t, cfg.AssetsDir, map[string]string{
+			"teal/index.yaml": yamlContent,
+			"navy.md":         markdownContent,
+			// Records that are current are left alone.
+			"blue/index.json": `{"header": {"schema": "paint", "schema_version": 2}, "properties": {"color": "blue"}}`,
+		})
+
+		err := gen.Migrate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 record(s)")
+
+		content, err := os.ReadFile(filepath.Join(cfg.AssetsDir, "teal", "index.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, yamlContent, string(content))
+		content, err = os.ReadFile(filepath.Join(cfg.AssetsDir, "navy.md"))
+		require.NoError(t, err)
+		assert.Equal(t, markdownContent, string(content))
+	})
+}
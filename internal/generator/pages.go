@@ -0,0 +1,256 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"logseq_gen/internal/schema"
+)
+
+// pageFilepath returns the file in the pages directory that holds the Logseq
+// page with the given name. Namespace separators become "___".
+func (g *Generator) pageFilepath(pageName string) string {
+	return filepath.Join(g.config.PagesDir, fmt.Sprintf("%s.md", strings.ReplaceAll(pageName, "/", "___")))
+}
+
+// recordFilepath returns the file of the page generated for the record at
+// currentPath. The record at the top of the assets directory is "index".
+func (g *Generator) recordFilepath(currentPath string) string {
+	if currentPath == "." {
+		currentPath = "index"
+	}
+	return g.pageFilepath(currentPath)
+}
+
+// keepPage keeps a page of the previous build as it is, so that it is not
+// removed as stale at the end of the build.
+func (g *Generator) keepPage(outputFilepath string) {
+	delete(g.previous, outputFilepath)
+}
+
+// writeGeneratedPage writes a generated page. An existing file is only
+// replaced if it was generated by the previous build; otherwise it is either
+// hand-written or was produced earlier in the same build.
+func (g *Generator) writeGeneratedPage(outputFilepath, content string) error {
+	return g.writePage(outputFilepath, generatedMarker+"\n"+content, false)
+}
+
+// writePage writes a page unless the previous build left an identical file,
+// so that unchanged pages keep their modification time. A file that the
+// previous build did not generate is only replaced with overwrite.
+func (g *Generator) writePage(outputFilepath, content string, overwrite bool) error {
+	previous := g.previous[outputFilepath]
+	delete(g.previous, outputFilepath)
+	if existing, err := os.ReadFile(outputFilepath); err == nil {
+		if previous && bytes.Equal(existing, []byte(content)) {
+			fmt.Printf("-> Unchanged %s\n", outputFilepath)
+			return nil
+		}
+		if !previous && !overwrite {
+			return fmt.Errorf("%s already exists", outputFilepath)
+		}
+	}
+	if err := os.WriteFile(outputFilepath, []byte(content), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("-> Generated %s\n", outputFilepath)
+	return nil
+}
+
+// generatedPages returns the generated files in the pages directory.
+func (g *Generator) generatedPages() (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(g.config.PagesDir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("error finding markdown files: %w", err)
+	}
+	pages := make(map[string]bool)
+	for _, file := range files {
+		generated, err := g.isGeneratedFile(file)
+		if err != nil {
+			log.Printf("Error checking if file %s is generated: %v", file, err)
+			continue
+		}
+		if generated {
+			pages[file] = true
+		}
+	}
+	return pages, nil
+}
+
+// removeStalePages removes the pages of the previous build that the current
+// build did not write.
+func (g *Generator) removeStalePages() {
+	stale := make([]string, 0, len(g.previous))
+	for file := range g.previous {
+		stale = append(stale, file)
+	}
+	sort.Strings(stale)
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil {
+			log.Printf("Error removing file %s: %v", file, err)
+		} else {
+			fmt.Printf("Removed %s\n", filepath.Base(file))
+		}
+	}
+	g.previous = nil
+}
+
+// defaultNamespaceTemplate lists the children of a namespace page, each with
+// its properties as block properties.
+const defaultNamespaceTemplate = `{{ range $child := .Children }}- {{ pageRef $child.Path }}
+{{ range $child.Keys }}  {{ . }}:: {{ index $child.Properties . }}
+{{ end }}{{ end }}`
+
+// generateNamespacePages writes an index page for every folder that holds
+// records but has no index.ini, if enabled in the configuration.
+func (g *Generator) generateNamespacePages() {
+	namespaces := g.site.namespaces()
+	if len(namespaces) == 0 {
+		return
+	}
+
+	var tmpl *template.Template
+	var err error
+	if g.config.NamespaceTemplate != "" {
+		tmpl, err = g.getTemplate(g.config.NamespaceTemplate)
+	} else {
+		tmpl, err = template.New("namespace").Funcs(templateFuncs()).Parse(defaultNamespaceTemplate)
+	}
+	if err != nil {
+		log.Printf("[SKIP] Could not get namespace template: %v", err)
+		return
+	}
+
+	for _, page := range namespaces {
+		data := templateData{
+			CurrentPath: page.Path,
+			Properties:  map[string]string{},
+			Raw:         map[string]string{},
+			Values:      map[string]interface{}{},
+		}
+		g.site.navigation(&data)
+		var content strings.Builder
+		if err := tmpl.Execute(&content, data); err != nil {
+			log.Printf("[SKIP] Could not execute namespace template for %s: %v", page.Path, err)
+			continue
+		}
+		g.writeSchemaPage(page.Path, "\n"+content.String())
+	}
+}
+
+// generateSchemaPages writes a page for every enum value and every property
+// declared by the schemas, if enabled in the configuration.
+func (g *Generator) generateSchemaPages() {
+	if !g.config.EnumPages && !g.config.PropertyPages {
+		return
+	}
+
+	names, err := g.schemas.Names()
+	if err != nil {
+		log.Printf("[SKIP] Could not list schemas: %v", err)
+		return
+	}
+
+	// Properties may be declared by several schemas; the first one (by name)
+	// provides the definition and the others are listed alongside it. Enum
+	// pages are written for the keys of every declaring schema.
+	definitions := make(map[string]schema.Type)
+	declaredBy := make(map[string][]string)
+	enums := make(map[string][]schema.Type)
+	for _, name := range names {
+		s, err := g.getSchema(name)
+		if err != nil {
+			continue
+		}
+		for key, typeDef := range s.Types {
+			if _, ok := definitions[key]; !ok {
+				definitions[key] = typeDef
+			}
+			declaredBy[key] = append(declaredBy[key], name)
+			if typeDef.Type == "enum" {
+				enums[key] = append(enums[key], typeDef)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(definitions))
+	for key := range definitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		typeDef := definitions[key]
+		if g.config.PropertyPages {
+			g.writeSchemaPage(key, propertyPageContent(key, typeDef, declaredBy[key]))
+		}
+		if !g.config.EnumPages {
+			continue
+		}
+		written := make(map[string]bool)
+		for _, enumDef := range enums[key] {
+			for _, enumName := range sortedEnumKeys(enumDef) {
+				enumKey := enumDef.Keys[enumName]
+				page := enumDef.EnumPage(key, enumName)
+				if enumKey.Deprecated && enumKey.ReplacedBy != "" || written[page] {
+					continue
+				}
+				written[page] = true
+				g.writeSchemaPage(page, enumPageContent(key, page, enumKey))
+			}
+		}
+	}
+}
+
+// writeSchemaPage writes one generated page that does not come from a record,
+// such as a schema or namespace page, and logs the result.
+func (g *Generator) writeSchemaPage(pageName, content string) {
+	outputFilepath := g.pageFilepath(pageName)
+	if err := g.writeGeneratedPage(outputFilepath, content); err != nil {
+		log.Printf("[SKIP] Could not write page %s: %v", pageName, err)
+	}
+}
+
+// propertyPageContent renders the page describing a schema property.
+func propertyPageContent(key string, typeDef schema.Type, schemas []string) string {
+	var content strings.Builder
+	if typeDef.Label != "" {
+		content.WriteString(fmt.Sprintf("label:: %s\n", typeDef.Label))
+	}
+	content.WriteString(fmt.Sprintf("type:: %s\n", typeDef.Type))
+	if typeDef.Description != "" {
+		content.WriteString(fmt.Sprintf("description:: %s\n", typeDef.Description))
+	}
+	content.WriteString(fmt.Sprintf("schemas:: %s\n", strings.Join(schemas, ", ")))
+	content.WriteString("\n")
+	content.WriteString(fmt.Sprintf("- {{query (property %s)}}\n", key))
+	return content.String()
+}
+
+// enumPageContent renders the page for a single enum value, including a
+// query listing every page that uses it.
+func enumPageContent(key, page string, enumKey schema.EnumKey) string {
+	var content strings.Builder
+	if enumKey.Description != "" {
+		content.WriteString(fmt.Sprintf("description:: %s\n", enumKey.Description))
+	}
+	content.WriteString("\n")
+	content.WriteString(fmt.Sprintf("- {{query (page-property %s \"%s\")}}\n", key, page))
+	return content.String()
+}
+
+// sortedEnumKeys returns the keys of an enum type in sorted order.
+func sortedEnumKeys(typeDef schema.Type) []string {
+	keys := make([]string, 0, len(typeDef.Keys))
+	for key := range typeDef.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
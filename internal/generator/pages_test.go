@@ -0,0 +1,126 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_SchemaPages(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.EnumPages = true
+	cfg.PropertyPages = true
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  status:\n" +
+		"    type: enum\n" +
+		"    description: Where the task stands\n" +
+		"    keys:\n" +
+		"      open:\n" +
+		"        display: Open\n" +
+		"        description: Not started yet\n" +
+		"      wip:\n" +
+		"        deprecated: true\n" +
+		"        replaced_by: open\n" +
+		"  title:\n" +
+		"    type: string\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"task.yaml": schemaContent})
+
+	// A hand-written page is never overwritten.
+	writeFiles(t, cfg.PagesDir, map[string]string{"title.md": "my notes\n"})
+	handWritten := filepath.Join(cfg.PagesDir, "title.md")
+
+	gen := generator.New(cfg)
+	require.NoError(t, gen.Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "status___Open.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\ndescription:: Not started yet\n\n- {{query (page-property status \"status/Open\")}}\n", string(content))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "status___wip.md"))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "status.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\ntype:: enum\ndescription:: Where the task stands\nschemas:: task\n\n- {{query (property status)}}\n", string(content))
+
+	content, err = os.ReadFile(handWritten)
+	require.NoError(t, err)
+	assert.Equal(t, "my notes\n", string(content))
+
+	// Generated pages are removed by Clear.
+	require.NoError(t, gen.Clear())
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "status.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "status___Open.md"))
+	assert.FileExists(t, handWritten)
+}
+
+func TestGenerator_Build_EnumPagesFromEverySchema(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.EnumPages = true
+	writeFiles(t, cfg.SchemaDir, map[string]string{
+		"bug.yaml":  "version: 1\ntypes:\n  status:\n    type: enum\n    keys:\n      open: {}\n",
+		"task.yaml": "version: 1\ntypes:\n  status:\n    type: enum\n    keys:\n      open: {}\n      closed: {}\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "status___open.md"))
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "status___closed.md"))
+}
+
+func TestGenerator_Build_HandWrittenPages(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.AssetsDir, map[string]string{"notes/index.ini": "[properties]\ntitle = Notes\n"})
+	// A page without the generated marker is hand-written, even if a record
+	// has the same name.
+	writeFiles(t, cfg.PagesDir, map[string]string{"notes.md": "- my notes\n"})
+	page := filepath.Join(cfg.PagesDir, "notes.md")
+
+	require.NoError(t, generator.New(cfg).Build())
+	content, err := os.ReadFile(page)
+	require.NoError(t, err)
+	assert.Equal(t, "- my notes\n", string(content))
+
+	require.NoError(t, os.Remove(page))
+	require.NoError(t, generator.New(cfg).Build())
+	content, err = os.ReadFile(page)
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\ntitle:: Notes\n\n", string(content))
+}
+
+func TestGenerator_Build_BrokenSchemaKeepsPages(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\ntypes:\n  pages:\n    type: number\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"dune/index.ini":   "[header]\nschema = book\n[properties]\npages = 412\n",
+		"shelf/index.ini":  "[header]\nschema = book\nrows = books.csv\n",
+		"shelf/books.csv":  "name,pages\nemma,474\n",
+		"loose/index.ini":  "[properties]\ntitle = Loose\n",
+		"broken/index.ini": "[header]\nschema = book\n[properties]\npages = many\n",
+	})
+	require.NoError(t, generator.New(cfg).Build())
+	dune := filepath.Join(cfg.PagesDir, "dune.md")
+	emma := filepath.Join(cfg.PagesDir, "shelf___emma.md")
+	require.FileExists(t, dune)
+	require.FileExists(t, emma)
+
+	// While the schema has errors, the pages of its records are kept as the
+	// previous build left them.
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\ntypes:\n  pages:\n    type: nubmer\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{"dune/index.ini": "[header]\nschema = book\n[properties]\npages = 413\n"})
+	require.NoError(t, generator.New(cfg).Build())
+	content, err := os.ReadFile(dune)
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\npages:: 412\n\n", string(content))
+	assert.FileExists(t, emma)
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "loose.md"))
+
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\ntypes:\n  pages:\n    type: number\n"})
+	require.NoError(t, generator.New(cfg).Build())
+	content, err = os.ReadFile(dune)
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\npages:: 413\n\n", string(content))
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// propertySet holds every property of a record once it has been validated.
+// It is read by the header writer, templates and content files alike and is
+// never modified after creation; accessors return copies.
+type propertySet struct {
+	keys        []string
+	raw         map[string]string
+	transformed map[string]string
+	values      map[string]interface{}
+}
+
+// newPropertySet creates a property set. The output order is the record's
+// own key order followed by any properties added by the schema, such as
+// defaults and computed values, in sorted order. Values that span several
+// lines cannot be page properties, so they are left out of the keys and
+// only reach templates.
+func newPropertySet(orderedKeys []string, raw, transformed map[string]string, values map[string]interface{}) *propertySet {
+	set := &propertySet{
+		raw:         copyStrings(raw),
+		transformed: copyStrings(transformed),
+		values:      make(map[string]interface{}, len(values)),
+	}
+	for key, value := range values {
+		set.values[key] = value
+	}
+
+	seen := make(map[string]bool, len(transformed))
+	for key, value := range transformed {
+		if strings.ContainsAny(value, "\r\n") {
+			seen[key] = true
+		}
+	}
+	for _, key := range orderedKeys {
+		if _, ok := transformed[key]; ok && !seen[key] {
+			set.keys = append(set.keys, key)
+			seen[key] = true
+		}
+	}
+	var added []string
+	for key := range transformed {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	set.keys = append(set.keys, added...)
+	return set
+}
+
+// Keys returns the property names in output order.
+func (p *propertySet) Keys() []string {
+	return append([]string(nil), p.keys...)
+}
+
+// Raw returns the input values, with defaults and computed values applied.
+func (p *propertySet) Raw() map[string]string {
+	return copyStrings(p.raw)
+}
+
+// Transformed returns the rendered Logseq values.
+func (p *propertySet) Transformed() map[string]string {
+	return copyStrings(p.transformed)
+}
+
+// Values returns the typed values.
+func (p *propertySet) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(p.values))
+	for key, value := range p.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Header renders the properties as Logseq page properties, followed by the
+// blank line that separates them from the body.
+func (p *propertySet) Header() string {
+	var header strings.Builder
+	for _, key := range p.keys {
+		header.WriteString(fmt.Sprintf("%s:: %s\n", key, p.transformed[key]))
+	}
+	header.WriteString("\n")
+	return header.String()
+}
+
+// templateData builds the data passed to templates for the page at currentPath.
+func (p *propertySet) templateData(currentPath string) templateData {
+	return templateData{
+		CurrentPath: currentPath,
+		Keys:        p.Keys(),
+		Properties:  p.Transformed(),
+		Raw:         p.Raw(),
+		Values:      p.Values(),
+	}
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
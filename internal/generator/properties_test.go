@@ -0,0 +1,63 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_PropertySet(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  property_a:\n" +
+		"    type: number\n" +
+		"  property_f:\n" +
+		"    type: date\n" +
+		"  property_z:\n" +
+		"    type: string\n" +
+		"    default: last\n" +
+		"  property_y:\n" +
+		"    type: string\n" +
+		"    default: middle\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"bbb.yaml": schemaContent})
+
+	templateContent := "- Property A: {{ .Properties.property_a }}\n" +
+		"- Property F: {{ .Properties.property_f }} / {{ .Raw.property_f }}\n" +
+		"- Keys:{{ range .Keys }} {{ . }}={{ index $.Properties . }}{{ end }}\n"
+	writeFiles(t, cfg.TemplateDir, map[string]string{"example_template.template": templateContent})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"templated/index.ini": "[header]\nschema = bbb\ntemplate = example_template\n[properties]\nproperty_f = 2025-09-21\nproperty_a = 10\n",
+		"content/index.ini":   "[header]\nschema = bbb\ncontent = body.md\n[properties]\nproperty_a = 10\n",
+		"content/body.md":     "- body\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	header := "generated:: true\n" +
+		"property_f:: [[2025-09-21]]\n" +
+		"property_a:: 10\n" +
+		"property_y:: middle\n" +
+		"property_z:: last\n" +
+		"\n"
+
+	t.Run("Header writer and template see every property", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "templated.md"))
+		require.NoError(t, err)
+		assert.Equal(t, header+
+			"- Property A: 10\n"+
+			"- Property F: [[2025-09-21]] / 2025-09-21\n"+
+			"- Keys: property_f=[[2025-09-21]] property_a=10 property_y=middle property_z=last\n", string(content))
+	})
+
+	t.Run("Content pages keep the full header", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "content.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "generated:: true\nproperty_a:: 10\nproperty_y:: middle\nproperty_z:: last\n\n- body\n", string(content))
+	})
+}
@@ -0,0 +1,516 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// recordHeader holds the header of a record file: schema, template, content
+// and the other settings that are not page properties.
+type recordHeader map[string]string
+
+// has reports whether the header sets key.
+func (h recordHeader) has(key string) bool {
+	_, ok := h[key]
+	return ok
+}
+
+// get returns the value of key, with surrounding quotes removed.
+func (h recordHeader) get(key string) string {
+	return strings.Trim(h[key], "\"")
+}
+
+// bool returns the value of key as a boolean, with every spelling index.ini
+// files accept, such as yes or on. Invalid values are false.
+func (h recordHeader) bool(key string) bool {
+	entry := ini.Empty().Section("").Key(key)
+	entry.SetValue(h.get(key))
+	return entry.MustBool(false)
+}
+
+// recordFile is the content of a record file, independent of its format.
+type recordFile struct {
+	header recordHeader
+	// keys lists the properties in the order they appear in the file.
+	keys       []string
+	properties map[string]string
+	// values holds the properties as a format with types of its own
+	// decoded them, so that schemas can check the types. It is nil for
+	// index.ini files, whose values are all strings.
+	values map[string]interface{}
+	// body is the page content carried by the file itself, as in markdown
+	// records.
+	body string
+	// blocks are added below the page content.
+	blocks []recordBlock
+}
+
+// recordLoader reads the record files of one format.
+type recordLoader interface {
+	Load(path string) (*recordFile, error)
+}
+
+// recordLoaders maps the file names recognized as records to their loader.
+var recordLoaders = map[string]recordLoader{
+	"index.ini":  iniLoader{},
+	"index.yaml": yamlLoader{},
+	"index.yml":  yamlLoader{},
+	"index.json": jsonLoader{},
+	"index.toml": tomlLoader{},
+}
+
+// markdownExt is the extension of markdown records. Unlike the other record
+// files, they may have any name, and are records only if they start with
+// front matter.
+const markdownExt = ".md"
+
+// findRecordFiles finds all record files in the assets directory. Markdown
+// records that another record uses as its content are dropped when the
+// records are loaded.
+func (g *Generator) findRecordFiles() ([]string, error) {
+	var recordFiles []string
+	err := filepath.Walk(g.config.AssetsDir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if _, ok := recordLoaders[info.Name()]; ok {
+			recordFiles = append(recordFiles, path)
+		} else if filepath.Ext(path) == markdownExt {
+			record, err := isMarkdownRecord(path)
+			if err != nil {
+				return err
+			}
+			if record {
+				recordFiles = append(recordFiles, path)
+			}
+		}
+		return nil
+	})
+	return recordFiles, err
+}
+
+// loadRecordFile reads a record file with the loader for its name.
+func loadRecordFile(path string) (*recordFile, error) {
+	if filepath.Ext(path) == markdownExt {
+		return markdownLoader{}.Load(path)
+	}
+	loader, ok := recordLoaders[filepath.Base(path)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record file %s", path)
+	}
+	return loader.Load(path)
+}
+
+// iniLoader reads index.ini files.
+type iniLoader struct{}
+
+func (iniLoader) Load(path string) (*recordFile, error) {
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &recordFile{header: iniHeader(cfg.Section("header")), properties: make(map[string]string)}
+	propertiesSection := cfg.Section("properties")
+	file.keys = propertiesSection.KeyStrings()
+	for _, key := range file.keys {
+		file.properties[key] = propertiesSection.Key(key).String()
+	}
+	file.blocks, err = iniBlocks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// iniHeader converts an ini [header] section.
+func iniHeader(section *ini.Section) recordHeader {
+	header := make(recordHeader)
+	for _, key := range section.Keys() {
+		header[key.Name()] = key.String()
+	}
+	return header
+}
+
+// field is one entry of a mapping, kept in file order.
+type field struct {
+	key   string
+	value interface{}
+}
+
+// newRecordFile builds a record file from the top-level mapping of a
+// structured format. Values may be scalars, lists or nested mappings; see
+// flattenProperty.
+func newRecordFile(fields []field) (*recordFile, error) {
+	file := &recordFile{header: make(recordHeader), properties: make(map[string]string), values: make(map[string]interface{})}
+	for _, f := range fields {
+		switch f.key {
+		case "header", "properties", "blocks":
+		default:
+			return nil, fmt.Errorf("unknown section '%s'; expected 'header', 'properties' or 'blocks'", f.key)
+		}
+		if f.value == nil {
+			continue
+		}
+		section, ok := f.value.([]field)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be a mapping", f.key)
+		}
+		if f.key == "blocks" {
+			if err := file.addBlocks(section); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, entry := range section {
+			if f.key == "header" {
+				value, err := scalarString(entry.value)
+				if err != nil {
+					return nil, fmt.Errorf("header '%s': %w", entry.key, err)
+				}
+				file.header[entry.key] = value
+				continue
+			}
+			if err := file.flattenProperty(entry.key, entry.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return file, nil
+}
+
+// flattenProperty adds a property value. Lists are joined with ", ", the
+// form enum properties accept; nested mappings become one property per
+// entry, named "parent.child"; null values are left out.
+func (file *recordFile) flattenProperty(key string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []field:
+		for _, entry := range v {
+			if err := file.flattenProperty(key+"."+entry.key, entry.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := scalarString(item)
+			if err != nil {
+				return fmt.Errorf("property '%s': %w", key, err)
+			}
+			if strings.Contains(s, ",") {
+				return fmt.Errorf("property '%s': list item '%s' contains a comma, which separates the items", key, s)
+			}
+			items = append(items, s)
+		}
+		return file.setProperty(key, strings.Join(items, ", "), v)
+	default:
+		s, err := scalarString(v)
+		if err != nil {
+			return fmt.Errorf("property '%s': %w", key, err)
+		}
+		return file.setProperty(key, s, v)
+	}
+}
+
+// setProperty adds a property in string form, keeping its decoded value if
+// the file records them.
+func (file *recordFile) setProperty(key, value string, decoded interface{}) error {
+	if _, exists := file.properties[key]; exists {
+		return fmt.Errorf("property '%s' is set more than once", key)
+	}
+	file.keys = append(file.keys, key)
+	file.properties[key] = value
+	if file.values != nil {
+		file.values[key] = decoded
+	}
+	return nil
+}
+
+// scalarString formats a scalar value the way it would be written in an
+// index.ini file, so that schema validation treats all formats alike.
+func scalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case time.Time:
+		// TOML local dates and times carry no offset; the decoder marks them
+		// with these locations.
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format("2006-01-02"), nil
+		case "time-local":
+			return v.Format("15:04:05"), nil
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05"), nil
+		}
+		return v.Format(time.RFC3339), nil
+	case []field, []interface{}:
+		return "", fmt.Errorf("nested lists and mappings are not supported here")
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// yamlLoader reads index.yaml and index.yml files.
+type yamlLoader struct{}
+
+func (yamlLoader) Load(path string) (*recordFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return yamlRecordFile(path, data)
+}
+
+// yamlRecordFile parses a YAML record.
+func yamlRecordFile(path string, data []byte) (*recordFile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return newRecordFile(nil)
+	}
+	root := yamlValue(doc.Content[0])
+	fields, ok := root.([]field)
+	if !ok {
+		return nil, fmt.Errorf("%s must contain a mapping", path)
+	}
+	return newRecordFile(fields)
+}
+
+// yamlValue converts a YAML node. Scalars keep the spelling they were
+// written with: numbers become json.Number, booleans bool and everything
+// else, including timestamps, a string.
+func yamlValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		fields := make([]field, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fields = append(fields, field{key: node.Content[i].Value, value: yamlValue(node.Content[i+1])})
+		}
+		return fields
+	case yaml.SequenceNode:
+		items := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			items[i] = yamlValue(child)
+		}
+		return items
+	default:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil
+		case "!!int", "!!float":
+			return json.Number(node.Value)
+		case "!!bool":
+			var b bool
+			if err := node.Decode(&b); err == nil {
+				return b
+			}
+		}
+		return node.Value
+	}
+}
+
+// markdownLoader reads markdown files that start with YAML front matter
+// holding the header and properties. The rest of the file is the body.
+type markdownLoader struct{}
+
+func (markdownLoader) Load(path string) (*recordFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	frontMatter, body, ok := splitFrontMatter(string(data))
+	if !ok {
+		return nil, fmt.Errorf("%s has no front matter", path)
+	}
+	file, err := yamlRecordFile(path, []byte(frontMatter))
+	if err != nil {
+		return nil, err
+	}
+	file.body = strings.TrimLeft(body, "\r\n")
+	return file, nil
+}
+
+// splitFrontMatter splits a document into the front matter between the
+// leading "---" line and the closing "---" or "..." line, and the body.
+func splitFrontMatter(data string) (frontMatter, body string, ok bool) {
+	lines := strings.SplitAfter(data, "\n")
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r\n") != "---" {
+		return "", "", false
+	}
+	for i := 1; i < len(lines); i++ {
+		switch strings.TrimRight(lines[i], "\r\n") {
+		case "---", "...":
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], ""), true
+		}
+	}
+	return "", "", false
+}
+
+// isMarkdownRecord reports whether the markdown file at path is a record:
+// it must start with front matter holding a mapping with a header or
+// properties section, so that a page that merely opens with a horizontal
+// rule is left alone.
+func isMarkdownRecord(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	frontMatter, _, ok := splitFrontMatter(string(data))
+	if !ok {
+		return false, nil
+	}
+	var sections map[string]interface{}
+	if err := yaml.Unmarshal([]byte(frontMatter), &sections); err != nil {
+		return false, nil
+	}
+	_, hasHeader := sections["header"]
+	_, hasProperties := sections["properties"]
+	return hasHeader || hasProperties, nil
+}
+
+// jsonLoader reads index.json files.
+type jsonLoader struct{}
+
+func (jsonLoader) Load(path string) (*recordFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := jsonValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := root.([]field)
+	if !ok {
+		return nil, fmt.Errorf("%s must contain an object", path)
+	}
+	return newRecordFile(fields)
+}
+
+// jsonValue decodes the next JSON value, keeping the order of object keys.
+func jsonValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		var fields []field
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := jsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field{key: keyToken.(string), value: value})
+		}
+		_, err := decoder.Token()
+		return fields, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for decoder.More() {
+			value, err := jsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		_, err := decoder.Token()
+		return items, err
+	default:
+		return token, nil
+	}
+}
+
+// tomlLoader reads index.toml files.
+type tomlLoader struct{}
+
+func (tomlLoader) Load(path string) (*recordFile, error) {
+	var data map[string]interface{}
+	meta, err := toml.DecodeFile(path, &data)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string]int)
+	for i, key := range meta.Keys() {
+		order[key.String()] = i
+	}
+	return newRecordFile(tomlValue(data, "", order).([]field))
+}
+
+// tomlValue converts a decoded TOML value, ordering table keys as they
+// appear in the file.
+func tomlValue(value interface{}, prefix string, order map[string]int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fields := make([]field, 0, len(v))
+		for key, child := range v {
+			fields = append(fields, field{key: key, value: tomlValue(child, joinTOMLKey(prefix, key), order)})
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return order[joinTOMLKey(prefix, fields[i].key)] < order[joinTOMLKey(prefix, fields[j].key)]
+		})
+		return fields
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item, prefix, order)
+		}
+		return items
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item, prefix, order)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// joinTOMLKey returns the dotted key of key within the table prefix.
+func joinTOMLKey(prefix, key string) string {
+	if prefix == "" {
+		return toml.Key{key}.String()
+	}
+	return prefix + "." + toml.Key{key}.String()
+}
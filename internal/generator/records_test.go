@@ -0,0 +1,169 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_RecordFormats(t *testing.T) {
+	cfg := newTestConfig(t)
+	schemaContent := "version: 1\n" +
+		"types:\n" +
+		"  pages:\n" +
+		"    type: number\n" +
+		"    required: true\n" +
+		"  read:\n" +
+		"    type: boolean\n" +
+		"  published:\n" +
+		"    type: date\n" +
+		"  genre:\n" +
+		"    type: enum\n" +
+		"    keys:\n" +
+		"      scifi:\n" +
+		"        display: Science Fiction\n" +
+		"      classic:\n" +
+		"        display: Classic\n"
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": schemaContent})
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": "- {{ .Raw.summary }}\n"})
+
+	files := map[string]string{
+		"ini/index.ini": "[header]\nschema = book\ntemplate = book\n" +
+			"[properties]\npages = 412\nread = true\npublished = 1965-08-01\ngenre = scifi, classic\n" +
+			"author.name = Frank Herbert\nsummary = A desert planet.\n",
+		"yaml/index.yaml": "header:\n  schema: book\n  template: book\n" +
+			"properties:\n  pages: 412\n  read: true\n  published: 1965-08-01\n  genre: [scifi, classic]\n" +
+			"  author:\n    name: Frank Herbert\n  summary: |-\n    A desert planet.\n",
+		"json/index.json": `{"header": {"schema": "book", "template": "book"},
+			"properties": {"pages": 412, "read": true, "published": "1965-08-01", "genre": ["scifi", "classic"],
+			"author": {"name": "Frank Herbert"}, "summary": "A desert planet."}}`,
+		"toml/index.toml": "[header]\nschema = \"book\"\ntemplate = \"book\"\n\n" +
+			"[properties]\npages = 412\nread = true\npublished = 1965-08-01\ngenre = [\"scifi\", \"classic\"]\n" +
+			"author = { name = \"Frank Herbert\" }\nsummary = \"\"\"\nA desert planet.\"\"\"\n",
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	expected := "generated:: true\n" +
+		"pages:: 412\n" +
+		"read:: true\n" +
+		"published:: [[1965-08-01]]\n" +
+		"genre:: [[genre/Science Fiction]] [[genre/Classic]]\n" +
+		"author.name:: Frank Herbert\n" +
+		"summary:: A desert planet.\n" +
+		"\n" +
+		"- A desert planet.\n"
+	for _, name := range []string{"ini", "yaml", "json", "toml"} {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, name+".md"))
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, string(content), name)
+	}
+}
+
+func TestGenerator_Check_RecordFormats(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\ntypes:\n" +
+		"  pages:\n    type: number\n  title:\n    type: string\n"})
+	files := map[string]string{
+		"valid/index.yaml": "properties:\n  title: Dune\n",
+		// Sections other than header and properties are rejected.
+		"unknown/index.json": `{"props": {"title": "Dune"}}`,
+		// Lists of mappings cannot be flattened into a property.
+		"nested/index.yaml": "properties:\n  authors:\n    - name: Frank\n",
+		// Two record files in one folder would generate the same page.
+		"twice/index.ini":  "[properties]\ntitle = Dune\n",
+		"twice/index.toml": "[properties]\ntitle = \"Dune\"\n",
+		// Block properties hold a single line.
+		"lines/index.yaml": "blocks:\n  plot:\n    summary: |\n      A desert planet.\n      Spice.\n",
+		// Commas separate the items of a list.
+		"comma/index.json": `{"properties": {"authors": ["Herbert, Frank"]}}`,
+		// Schemas check the types the format decoded: a quoted number is
+		// text, and only enum properties take lists.
+		"quoted/index.yaml": "header:\n  schema: book\nproperties:\n  pages: \"412\"\n",
+		"listed/index.json": `{"header": {"schema": "book"}, "properties": {"title": ["Dune", "Emma"]}}`,
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	err := generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "7 invalid record(s)")
+}
+
+func TestGenerator_Build_MultiLineValues(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": "{{ .Raw.summary }}\n- {{ len .Keys }} {{ printf \"%T\" .Values.summary }}\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"dune/index.yaml": "header:\n  template: book\n" +
+			"properties:\n  pages: 412\n  summary: |-\n    - A desert planet.\n    - Spice.\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	// Page properties hold a single line, so the summary only reaches the
+	// template.
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "dune.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\npages:: 412\n\n- A desert planet.\n- Spice.\n- 1 string\n", string(content))
+}
+
+func TestGenerator_Build_MarkdownRecords(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\ntypes:\n  pages:\n    type: number\n    required: true\n"})
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": "- {{ .Raw.pages }} pages\n{{ indent 1 .Content }}"})
+
+	files := map[string]string{
+		// The body is copied after the properties.
+		"notes/dune.md": "---\nheader:\n  schema: book\nproperties:\n  pages: 412\n---\n\n- A desert planet.\n",
+		// index.md describes its folder.
+		"notes/index.md": "---\nproperties:\n  title: Notes\n---\n- All notes\n",
+		// A template receives the body as .Content.
+		"books/emma.md": "---\nheader:\n  schema: book\n  template: book\nproperties:\n  pages: 474\n---\n- Matchmaking\n",
+		// content_render renders the body itself.
+		"books/alien.md": "---\nheader:\n  content_render: true\nproperties:\n  year: 1979\n...\n- Released {{ .Raw.year }}\n",
+		// Invalid records are skipped like any other.
+		"books/bad.md": "---\nheader:\n  schema: book\n---\n- No pages\n",
+		// Markdown files without front matter are not records.
+		"books/plain.md": "- Just notes\n",
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	expected := map[string]string{
+		"notes___dune.md":  "generated:: true\npages:: 412\n\n- A desert planet.\n",
+		"notes.md":         "generated:: true\ntitle:: Notes\n\n- All notes\n",
+		"books___emma.md":  "generated:: true\npages:: 474\n\n- 474 pages\n\t- Matchmaking\n",
+		"books___alien.md": "generated:: true\nyear:: 1979\n\n- Released 1979\n",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, want, string(content), name)
+	}
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "books___bad.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "books___plain.md"))
+}
+
+func TestGenerator_Check_MarkdownContentFiles(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		// A page that opens with a horizontal rule has no front matter.
+		"notes/index.ini": "[header]\ncontent = notes.md\n[properties]\ntitle = Notes\n",
+		"notes/notes.md":  "---\nFirst part\n---\nSecond part\n",
+		// A content file with front matter belongs to its record.
+		"essay/index.ini": "[header]\ncontent = essay.md\n[properties]\ntitle = Essay\n",
+		"essay/essay.md":  "---\nproperties:\n  draft: true\n---\n- Body\n",
+	})
+
+	gen := generator.New(cfg)
+	require.NoError(t, gen.Check())
+	require.NoError(t, gen.Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "essay.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "essay___essay.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "notes___notes.md"))
+}
@@ -0,0 +1,299 @@
+package generator
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	// Registers the pure Go "sqlite" driver.
+	_ "modernc.org/sqlite"
+)
+
+// rowNameData is the data passed to row_name patterns.
+type rowNameData struct {
+	Properties map[string]string
+}
+
+// rowSource yields the rows of a tabular source, such as a CSV file or the
+// result of an SQLite query.
+type rowSource interface {
+	// Columns returns the column names.
+	Columns() []string
+	// Next returns the next row and where it comes from, for messages, or
+	// io.EOF after the last row.
+	Next() (row []string, location string, err error)
+	Close() error
+}
+
+// hasRows reports whether a record file describes a tabular source rather
+// than a single page.
+func hasRows(header recordHeader) bool {
+	return header.has("rows") || header.has("sqlite")
+}
+
+// openRows opens the tabular source described by a record file's header:
+// the CSV or TSV file named by rows, or the query run against the SQLite
+// database named by sqlite. Paths are relative to the record file.
+func openRows(filePath string, header recordHeader) (rowSource, error) {
+	dir := filepath.Dir(filePath)
+	if header.has("sqlite") {
+		if !header.has("query") {
+			return nil, fmt.Errorf("sqlite requires a query")
+		}
+		return openSQLiteRows(filepath.Join(dir, header.get("sqlite")), header.get("query"))
+	}
+	return openCSVRows(filepath.Join(dir, header.get("rows")))
+}
+
+// loadRows loads the tabular source described by a record file, turning
+// every row into a record of its own. Columns become properties. The record
+// file's own properties are shared by all rows, and a non-empty value
+// overrides them. Each row is a page in the record file's folder, named
+// after the row_name column, or the first column if row_name is not set.
+// row_name may also be a pattern such as "{{ .Properties.title | slug }}".
+// Failed rows are logged and counted. A row whose hash is unchanged since
+// the previous build is not validated again.
+func (g *Generator) loadRows(filePath string, recordFile *recordFile) ([]*record, int) {
+	headerSection := recordFile.header
+	folder, err := g.currentPath(filePath)
+	if err != nil {
+		log.Printf("[SKIP] Could not determine relative path for %s: %v", filePath, err)
+		return nil, 1
+	}
+
+	rows, err := openRows(filePath, headerSection)
+	if err != nil {
+		log.Printf("[SKIP] Could not read the rows of %s: %v", filePath, err)
+		return nil, 1
+	}
+	defer rows.Close()
+
+	columns := rows.Columns()
+	if len(columns) == 0 {
+		log.Printf("[SKIP] The rows of %s have no columns", filePath)
+		return nil, 1
+	}
+	namePattern := headerSection.get("row_name")
+	if namePattern == "" {
+		namePattern = columns[0]
+	}
+	if !strings.Contains(namePattern, "{{") {
+		namePattern = fmt.Sprintf("{{ index .Properties %q }}", namePattern)
+	}
+	namer, err := template.New("row_name").Funcs(templateFuncs()).Option("missingkey=zero").Parse(namePattern)
+	if err != nil {
+		log.Printf("[SKIP] Invalid row_name in %s: %v", filePath, err)
+		return nil, 1
+	}
+
+	sharedKeys := recordFile.keys
+	inputs, err := g.rowInputs(filePath, headerSection)
+	if err != nil {
+		log.Printf("[SKIP] Could not read the inputs of %s: %v", filePath, err)
+		return nil, 1
+	}
+
+	var records []*record
+	failed := 0
+	for {
+		row, source, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// A malformed line only fails its own row.
+			log.Printf("[SKIP] Could not parse %s: %v", source, parseErr.Err)
+			failed++
+			continue
+		}
+		if err != nil {
+			log.Printf("[SKIP] Could not read %s: %v", source, err)
+			failed++
+			break
+		}
+
+		orderedKeys := append([]string(nil), sharedKeys...)
+		props := make(map[string]string, len(columns)+len(sharedKeys))
+		decoded := make(map[string]interface{}, len(recordFile.values))
+		for _, key := range sharedKeys {
+			props[key] = recordFile.properties[key]
+			if value, ok := recordFile.values[key]; ok {
+				decoded[key] = value
+			}
+		}
+		for i, column := range columns {
+			if column == "" || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+			if _, shared := props[column]; !shared {
+				orderedKeys = append(orderedKeys, column)
+			}
+			props[column] = strings.TrimSpace(row[i])
+			delete(decoded, column)
+		}
+
+		var name strings.Builder
+		if err := namer.Execute(&name, rowNameData{Properties: props}); err != nil {
+			log.Printf("[SKIP] Could not name the page of %s: %v", source, err)
+			failed++
+			continue
+		}
+		pageName := strings.Trim(strings.TrimSpace(name.String()), "/")
+		if pageName == "" || path.Clean(pageName) != pageName || strings.HasPrefix(pageName, "..") {
+			log.Printf("[SKIP] The page of %s has an invalid name '%s'", source, pageName)
+			failed++
+			continue
+		}
+		currentPath := path.Join(folder, pageName)
+		hash := rowHash(inputs, orderedKeys, props)
+
+		if set, ok := g.unchangedRow(source, currentPath, hash, headerSection); ok {
+			records = append(records, &record{filePath: filePath, source: source, path: currentPath, header: headerSection, props: set, blocks: recordFile.blocks, hash: hash, unchanged: true})
+			continue
+		}
+		set, ok := g.loadProperties(source, currentPath, headerSection, orderedKeys, props, decoded)
+		if !ok {
+			failed++
+			continue
+		}
+		records = append(records, &record{filePath: filePath, source: source, path: currentPath, header: headerSection, props: set, blocks: recordFile.blocks, hash: hash})
+	}
+	return records, failed
+}
+
+// csvRows reads a CSV file, or a TSV file by its .tsv extension. The first
+// line holds the column names.
+type csvRows struct {
+	path    string
+	file    *os.File
+	reader  *csv.Reader
+	columns []string
+}
+
+func openCSVRows(rowsPath string) (*csvRows, error) {
+	file, err := os.Open(rowsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	if strings.EqualFold(filepath.Ext(rowsPath), ".tsv") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not read the columns of %s: %w", rowsPath, err)
+	}
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+	}
+	return &csvRows{path: rowsPath, file: file, reader: reader, columns: columns}, nil
+}
+
+func (r *csvRows) Columns() []string {
+	return r.columns
+}
+
+func (r *csvRows) Next() ([]string, string, error) {
+	row, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, fmt.Sprintf("%s:%d", r.path, parseErr.StartLine), err
+	}
+	line, _ := r.reader.FieldPos(0)
+	return row, fmt.Sprintf("%s:%d", r.path, line), err
+}
+
+func (r *csvRows) Close() error {
+	return r.file.Close()
+}
+
+// sqliteRows reads the result of a query against an SQLite database, which
+// is opened read-only.
+type sqliteRows struct {
+	path    string
+	db      *sql.DB
+	rows    *sql.Rows
+	columns []string
+	count   int
+}
+
+func openSQLiteRows(dbPath, query string) (*sqliteRows, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(dbPath)+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("query failed on %s: %w", dbPath, err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		db.Close()
+		return nil, err
+	}
+	return &sqliteRows{path: dbPath, db: db, rows: rows, columns: columns}, nil
+}
+
+func (r *sqliteRows) Columns() []string {
+	return r.columns
+}
+
+func (r *sqliteRows) Next() ([]string, string, error) {
+	r.count++
+	location := fmt.Sprintf("%s row %d", r.path, r.count)
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, location, err
+		}
+		return nil, location, io.EOF
+	}
+
+	values := make([]interface{}, len(r.columns))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return nil, location, err
+	}
+	row := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case []byte:
+			row[i] = string(v)
+		default:
+			s, err := scalarString(v)
+			if err != nil {
+				return nil, location, fmt.Errorf("column '%s': %w", r.columns[i], err)
+			}
+			row[i] = s
+		}
+	}
+	return row, location, nil
+}
+
+func (r *sqliteRows) Close() error {
+	r.rows.Close()
+	return r.db.Close()
+}
@@ -0,0 +1,207 @@
+package generator_test
+
+import (
+	"database/sql"
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_Rows(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"book.yaml": "version: 1\n" +
+		"types:\n" +
+		"  id:\n" +
+		"    type: string\n" +
+		"    unique: true\n" +
+		"  pages:\n" +
+		"    type: number\n" +
+		"    required: true\n" +
+		"  shelf:\n" +
+		"    type: string\n"})
+	writeFiles(t, cfg.TemplateDir, map[string]string{"book.template": "- {{ .CurrentPath }} on {{ .Raw.shelf }}\n"})
+
+	files := map[string]string{
+		"books/index.ini": "[header]\nschema = book\ntemplate = book\nrows = books.csv\nrow_name = id\n" +
+			"[properties]\nshelf = A\n",
+		"books/books.csv": "id, pages, shelf\n" +
+			"dune, 412,\n" +
+			"emma, 474, B\n" +
+			"dune, 100,\n" + // duplicate unique id
+			"blank, ,\n", // missing required pages
+		"films/index.ini": "[header]\nrows = films.tsv\nrow_name = {{ .Properties.title | slug }}\n",
+		"films/films.tsv": "title\tyear\n" +
+			"The Matrix\t1999\n" +
+			"Alien\t1979\n",
+	}
+	writeFiles(t, cfg.AssetsDir, files)
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "books___dune.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nshelf:: A\nid:: dune\npages:: 412\n\n- books/dune on A\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "books___emma.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nshelf:: B\nid:: emma\npages:: 474\n\n- books/emma on B\n", string(content))
+
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "books___blank.md"))
+	// The index.ini describing the rows does not get a page of its own.
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "books.md"))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "films___the-matrix.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\ntitle:: The Matrix\nyear:: 1999\n\n", string(content))
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "films___alien.md"))
+}
+
+func TestGenerator_Check_Rows(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"items/index.ini": "[header]\nrows = items.csv\n",
+		"items/items.csv": "name\na\nb\na\nbare\"quote\nc\n",
+	})
+
+	err := generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 invalid record(s)")
+
+	// A malformed line does not stop the rows after it.
+	require.NoError(t, generator.New(cfg).Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "items___c.md"))
+}
+
+func TestGenerator_Build_SQLiteRows(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"item.yaml": "version: 1\n" +
+		"types:\n" +
+		"  quantity:\n" +
+		"    type: number\n" +
+		"    required: true\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{"inventory/index.ini": "[header]\n" +
+		"schema = item\n" +
+		"sqlite = inventory.db\n" +
+		"query = SELECT name, quantity, note FROM items ORDER BY name\n" +
+		"[properties]\nlocation = shed\n"})
+
+	db, err := sql.Open("sqlite", filepath.Join(cfg.AssetsDir, "inventory", "inventory.db"))
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE items (name TEXT, quantity INTEGER, note TEXT);" +
+		"INSERT INTO items VALUES ('rake', 2, 'green'), ('saw', 1, NULL), ('broken', NULL, NULL);")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "inventory___rake.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nlocation:: shed\nname:: rake\nquantity:: 2\nnote:: green\n\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "inventory___saw.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nlocation:: shed\nname:: saw\nquantity:: 1\n\n", string(content))
+
+	// The row without a quantity fails validation.
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "inventory___broken.md"))
+}
+
+func TestGenerator_Build_Incremental(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"items/index.ini": "[header]\nrows = items.csv\n",
+		"items/items.csv": "name,count\na,1\nb,2\nc,3\n",
+	})
+	csvPath := filepath.Join(cfg.AssetsDir, "items", "items.csv")
+	require.NoError(t, generator.New(cfg).Build())
+
+	// Backdate the pages to see which ones the next build rewrites.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"items___a.md", "items___b.md", "items___c.md"} {
+		require.NoError(t, os.Chtimes(filepath.Join(cfg.PagesDir, name), past, past))
+	}
+
+	require.NoError(t, os.WriteFile(csvPath, []byte("name,count\na,1\nb,5\n"), 0644))
+	require.NoError(t, generator.New(cfg).Build())
+
+	info, err := os.Stat(filepath.Join(cfg.PagesDir, "items___a.md"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(past), "unchanged page was rewritten")
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "items___b.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nname:: b\ncount:: 5\n\n", string(content))
+
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "items___c.md"))
+
+	// A build that stops early keeps the pages of the previous build.
+	require.NoError(t, os.RemoveAll(cfg.AssetsDir))
+	require.Error(t, generator.New(cfg).Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "items___a.md"))
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, "items___b.md"))
+}
+
+func TestGenerator_Build_UnchangedRows(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.SchemaDir, map[string]string{"item.yaml": "version: 1\ntypes:\n  name:\n    type: string\n    unique: true\n"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"items/index.ini": "[header]\nschema = item\nrows = items.csv\n",
+		"items/items.csv": "name,count\na,1\nb,2\n",
+	})
+	csvPath := filepath.Join(cfg.AssetsDir, "items", "items.csv")
+	pageA := filepath.Join(cfg.PagesDir, "items___a.md")
+	require.NoError(t, generator.New(cfg).Build())
+	assert.FileExists(t, filepath.Join(cfg.PagesDir, ".logseq_gen_rows.json"))
+
+	// Replace the page of row a to see whether the next build renders it.
+	stale := "generated:: true\nname:: a\n\n- stale\n"
+	require.NoError(t, os.WriteFile(pageA, []byte(stale), 0644))
+
+	// Row a is unchanged and still claims its unique name.
+	require.NoError(t, os.WriteFile(csvPath, []byte("name,count\na,1\nb,5\na,9\n"), 0644))
+	require.NoError(t, generator.New(cfg).Build())
+	content, err := os.ReadFile(pageA)
+	require.NoError(t, err)
+	assert.Equal(t, stale, string(content))
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "items___b.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nname:: b\ncount:: 5\n\n", string(content))
+
+	// A change to the schemas changes the hash of every row.
+	writeFiles(t, cfg.SchemaDir, map[string]string{"other.yaml": "version: 1\ntypes: {}\n"})
+	require.NoError(t, generator.New(cfg).Build())
+	content, err = os.ReadFile(pageA)
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nname:: a\ncount:: 1\n\n", string(content))
+
+	// Check validates every row.
+	err = generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 invalid record(s)")
+
+	require.NoError(t, generator.New(cfg).Clear())
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, ".logseq_gen_rows.json"))
+}
+
+func TestGenerator_Build_UnchangedTemplateRows(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.TemplateDir, map[string]string{"item.template": "{{ range .Siblings }}- {{ .Name }}\n{{ end }}"})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"items/index.ini": "[header]\ntemplate = item\nrows = items.csv\n",
+		"items/items.csv": "name\na\nb\n",
+	})
+	require.NoError(t, generator.New(cfg).Build())
+
+	// Templates can list the other pages, so a new row renders the others
+	// again.
+	writeFiles(t, cfg.AssetsDir, map[string]string{"items/items.csv": "name\na\nb\nc\n"})
+	require.NoError(t, generator.New(cfg).Build())
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "items___a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nname:: a\n\n- b\n- c\n", string(content))
+}
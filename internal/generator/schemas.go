@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"logseq_gen/internal/schema"
+)
+
+// ExportSchema writes the named schema as a JSON Schema document to output,
+// or to standard output if output is empty.
+func (g *Generator) ExportSchema(name, output string) error {
+	s, err := g.getSchema(name)
+	if err != nil {
+		return fmt.Errorf("could not load schema '%s': %w", name, err)
+	}
+
+	data, err := s.ExportJSONSchema()
+	if err != nil {
+		return fmt.Errorf("could not export schema '%s': %w", name, err)
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", output, err)
+	}
+	fmt.Printf("-> Exported schema '%s' to %s\n", name, output)
+	return nil
+}
+
+// ImportSchema converts a JSON Schema document into a native YAML schema
+// named name in the schema directory. If name is empty, it is derived from
+// the input filename.
+func (g *Generator) ImportSchema(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	s, err := schema.ImportJSONSchema(data)
+	if err != nil {
+		return fmt.Errorf("could not import %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		name = strings.TrimSuffix(name, ".schema")
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("could not encode schema '%s': %w", name, err)
+	}
+
+	if err := os.MkdirAll(g.config.SchemaDir, 0755); err != nil {
+		return fmt.Errorf("could not create schema directory: %w", err)
+	}
+	schemaFile := filepath.Join(g.config.SchemaDir, fmt.Sprintf("%s.yaml", name))
+	if _, err := os.Stat(schemaFile); err == nil {
+		return fmt.Errorf("schema file %s already exists", schemaFile)
+	}
+	if err := os.WriteFile(schemaFile, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", schemaFile, err)
+	}
+	fmt.Printf("-> Imported %s as schema '%s'\n", path, name)
+	return nil
+}
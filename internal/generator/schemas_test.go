@@ -0,0 +1,39 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_ImportExportSchema(t *testing.T) {
+	cfg := newTestConfig(t)
+	tempDir := t.TempDir()
+
+	input := filepath.Join(tempDir, "book.schema.json")
+	require.NoError(t, os.WriteFile(input, []byte(`{
+		"type": "object",
+		"properties": {"pages": {"type": "integer", "minimum": 1}},
+		"required": ["pages"]
+	}`), 0644))
+
+	gen := generator.New(cfg)
+	require.NoError(t, gen.ImportSchema(input, ""))
+
+	content, err := os.ReadFile(filepath.Join(cfg.SchemaDir, "book.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\ntypes:\n  pages:\n    required: true\n    type: number\n    minimum: 1\n", string(content))
+
+	assert.Error(t, gen.ImportSchema(input, ""), "existing schemas are not overwritten")
+
+	output := filepath.Join(tempDir, "exported.json")
+	require.NoError(t, gen.ExportSchema("book", output))
+	exported, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(exported), `"required": [`)
+	assert.Contains(t, string(exported), `"minimum": 1`)
+}
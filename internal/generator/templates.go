@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const templateExt = ".template"
+
+// templateFile is a parsed template file: its own template, named after the
+// file, and the templates it defines with {{ define }} or {{ block }}.
+type templateFile struct {
+	name  string
+	trees map[string]*parse.Tree
+	// invoked holds the names the file calls with {{ template }} or {{ block }}.
+	invoked map[string]bool
+	// err is set if the file could not be parsed.
+	err error
+}
+
+// loadTemplates parses every .template file below the template directory.
+// Each file is named after its path relative to the directory, without the
+// extension, e.g. "partials/owner". Files that cannot be read or parsed are
+// logged and kept with their error, so that only the records using them fail.
+func (g *Generator) loadTemplates() map[string]*templateFile {
+	files := make(map[string]*templateFile)
+	if _, err := os.Stat(g.config.TemplateDir); os.IsNotExist(err) {
+		return files
+	}
+	err := filepath.WalkDir(g.config.TemplateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("[SKIP] Could not read %s: %v", path, err)
+			if d != nil && d.IsDir() && path != g.config.TemplateDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != templateExt {
+			return nil
+		}
+		relPath, err := filepath.Rel(g.config.TemplateDir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(relPath), templateExt)
+		file, err := parseTemplateFile(name, path)
+		if err != nil {
+			log.Printf("[SKIP] %v", err)
+			file = &templateFile{name: name, err: err}
+		}
+		files[name] = file
+		return nil
+	})
+	if err != nil {
+		log.Printf("[SKIP] Could not load the templates of %s: %v", g.config.TemplateDir, err)
+	}
+	return files
+}
+
+// parseTemplateFile reads and parses the template file at path under name.
+func parseTemplateFile(name, path string) (*templateFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read template file %s: %w", path, err)
+	}
+	return parseTemplate(name, string(content))
+}
+
+// parseTemplate parses text as the template name.
+func parseTemplate(name, text string) (*templateFile, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse template %s: %w", name, err)
+	}
+	file := &templateFile{name: name, trees: make(map[string]*parse.Tree), invoked: make(map[string]bool)}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		file.trees[t.Name()] = t.Tree
+		collectInvoked(t.Tree.Root, file.invoked)
+	}
+	return file, nil
+}
+
+// collectInvoked adds the names of all templates called below node to names.
+func collectInvoked(node parse.Node, names map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectInvoked(child, names)
+		}
+	case *parse.TemplateNode:
+		names[n.Name] = true
+	case *parse.IfNode:
+		collectInvoked(n.List, names)
+		collectInvoked(n.ElseList, names)
+	case *parse.RangeNode:
+		collectInvoked(n.List, names)
+		collectInvoked(n.ElseList, names)
+	case *parse.WithNode:
+		collectInvoked(n.List, names)
+		collectInvoked(n.ElseList, names)
+	}
+}
+
+// getTemplate returns the template with the given name, ready to execute.
+// Every template file is available to it, so that it can include partials
+// and fill in the {{ block }}s of a shared layout with its own {{ define }}s.
+func (g *Generator) getTemplate(name string) (*template.Template, error) {
+	if tmpl, ok := g.templateCache[name]; ok {
+		return tmpl, nil
+	}
+	g.ensureTemplates()
+	page, ok := g.templateFiles[name]
+	if !ok {
+		return nil, fmt.Errorf("template %s not found in %s", name, g.config.TemplateDir)
+	}
+	if page.err != nil {
+		return nil, page.err
+	}
+	tmpl, err := g.assembleTemplate(page)
+	if err != nil {
+		return nil, err
+	}
+	g.templateCache[name] = tmpl
+	return tmpl, nil
+}
+
+// getLocalTemplate returns the template stored next to a record at path,
+// e.g. an asset's index.template. Like shared templates, it can use every
+// file of the template directory.
+func (g *Generator) getLocalTemplate(path string) (*template.Template, error) {
+	if tmpl, ok := g.templateCache[path]; ok {
+		return tmpl, nil
+	}
+	g.ensureTemplates()
+	name := filepath.ToSlash(path)
+	if relPath, err := filepath.Rel(g.config.AssetsDir, path); err == nil {
+		name = filepath.ToSlash(relPath)
+	}
+	page, err := parseTemplateFile(name, path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := g.assembleTemplate(page)
+	if err != nil {
+		return nil, err
+	}
+	g.templateCache[path] = tmpl
+	return tmpl, nil
+}
+
+// getInlineTemplate returns a template for text that is not stored in a file
+// of its own, such as the body of a markdown record. It is not cached.
+func (g *Generator) getInlineTemplate(name, text string) (*template.Template, error) {
+	g.ensureTemplates()
+	page, err := parseTemplate(name, text)
+	if err != nil {
+		return nil, err
+	}
+	return g.assembleTemplate(page)
+}
+
+// ensureTemplates loads the template directory on first use.
+func (g *Generator) ensureTemplates() {
+	if g.templateFiles == nil {
+		g.templateFiles = g.loadTemplates()
+	}
+}
+
+// assembleTemplate combines page with the templates of the template
+// directory into an executable template.
+func (g *Generator) assembleTemplate(page *templateFile) (*template.Template, error) {
+	tmpl := template.New(page.name).Funcs(templateFuncs())
+	for _, file := range g.sortedTemplateFiles() {
+		if file == page || file.err != nil {
+			continue
+		}
+		for treeName, tree := range file.trees {
+			if g.isOverride(file, treeName) {
+				continue
+			}
+			if _, err := tmpl.AddParseTree(treeName, tree); err != nil {
+				return nil, fmt.Errorf("could not add template %s: %w", treeName, err)
+			}
+		}
+	}
+	// The page's own definitions are added last so that they take precedence.
+	for treeName, tree := range page.trees {
+		if _, err := tmpl.AddParseTree(treeName, tree); err != nil {
+			return nil, fmt.Errorf("could not add template %s: %w", treeName, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// isOverride reports whether the template treeName, defined in file, replaces
+// a template of another file, such as a layout's {{ block }}. Overrides only
+// apply when file itself is rendered, so that pages do not affect each other.
+func (g *Generator) isOverride(file *templateFile, treeName string) bool {
+	if treeName == file.name || file.invoked[treeName] {
+		return false
+	}
+	for _, other := range g.templateFiles {
+		if other != file && other.invoked[treeName] {
+			if _, ok := other.trees[treeName]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedTemplateFiles returns the template files in name order.
+func (g *Generator) sortedTemplateFiles() []*templateFile {
+	names := make([]string, 0, len(g.templateFiles))
+	for name := range g.templateFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*templateFile, len(names))
+	for i, name := range names {
+		files[i] = g.templateFiles[name]
+	}
+	return files
+}
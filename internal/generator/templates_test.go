@@ -0,0 +1,124 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_TemplateSet(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.TemplateDir, map[string]string{
+		"partials/owner.template":  `owner:: {{ pageRef .Raw.owner }}`,
+		"partials/macros.template": `{{ define "macros/title" }}# {{ .CurrentPath }}{{ end }}`,
+		"layouts/base.template": "{{ template \"macros/title\" . }}\n" +
+			"- {{ block \"body\" . }}No body{{ end }}\n" +
+			"- {{ template \"partials/owner\" . }}\n",
+		"books/book.template": `{{ template "layouts/base" . }}{{ define "body" }}Book by {{ .Raw.author }}{{ end }}`,
+		"plain.template":      `{{ template "layouts/base" . }}`,
+		"notes.txt":           `{{ this is not a template`,
+		// A template that does not parse only fails the records using it.
+		"broken.template": `{{ if }}`,
+		"fails.template":  `{{ template "nowhere" . }}`,
+	})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"dune/index.ini":    "[header]\ntemplate = books/book\n[properties]\nowner = alice\nauthor = Frank Herbert\n",
+		"other/index.ini":   "[header]\ntemplate = plain\n[properties]\nowner = bob\n",
+		"bad/index.ini":     "[header]\ntemplate = broken\n[properties]\nowner = carol\n",
+		"missing/index.ini": "[header]\ntemplate = nowhere\n[properties]\nowner = dave\n",
+		"fails/index.ini":   "[header]\ntemplate = fails\n[properties]\nowner = erin\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "dune.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nowner:: alice\nauthor:: Frank Herbert\n\n"+
+		"# dune\n- Book by Frank Herbert\n- owner:: [[alice]]\n", string(content))
+
+	// A page that does not define the block gets the layout's default, not
+	// the definition of another page.
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "other.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nowner:: bob\n\n"+
+		"# other\n- No body\n- owner:: [[bob]]\n", string(content))
+
+	// A template that is broken, missing or fails to execute fails the
+	// record, in build and in check alike.
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "bad.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "missing.md"))
+	assert.NoFileExists(t, filepath.Join(cfg.PagesDir, "fails.md"))
+
+	err = generator.New(cfg).Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 invalid record(s)")
+}
+
+func TestGenerator_Build_LocalTemplates(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeFiles(t, cfg.TemplateDir, map[string]string{
+		"partials/owner.template": `owner: {{ pageRef .Raw.owner }}`,
+	})
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		// index.template is picked up without a template or content key.
+		"auto/index.ini":      "[properties]\nowner = alice\n",
+		"auto/index.template": "- {{ .CurrentPath }} {{ template \"partials/owner\" . }}\n",
+		// An explicit path relative to the record's directory.
+		"explicit/index.ini":            "[header]\ntemplate = layout/page.template\n[properties]\nowner = bob\n",
+		"explicit/layout/page.template": "- Page of {{ tag .Raw.owner }}\n",
+		// content keeps copying the file verbatim.
+		"verbatim/index.ini":      "[header]\ncontent = index.template\n[properties]\nowner = carol\n",
+		"verbatim/index.template": "- {{ .CurrentPath }}\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "auto.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nowner:: alice\n\n- auto owner: [[alice]]\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "explicit.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nowner:: bob\n\n- Page of #bob\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "verbatim.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nowner:: carol\n\n- {{ .CurrentPath }}\n", string(content))
+}
+
+func TestGenerator_Build_RenderedContent(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"flag/index.ini":    "[header]\ncontent = notes.md\ncontent_render = true\n[properties]\nowner = alice\n",
+		"flag/notes.md":     "- Notes of {{ pageRef .Properties.owner }} in {{ .CurrentPath }}\n",
+		"ext/index.ini":     "[header]\ncontent = notes.md.tmpl\n[properties]\nowner = bob\n",
+		"ext/notes.md.tmpl": "- Notes of {{ .Raw.owner }}\n",
+		"off/index.ini":     "[header]\ncontent = notes.md.tmpl\ncontent_render = false\n[properties]\nowner = carol\n",
+		"off/notes.md.tmpl": "- Notes of {{ .Raw.owner }}\n",
+		"copy/index.ini":    "[header]\ncontent = notes.md\n[properties]\nowner = dave\n",
+		"copy/notes.md":     "- Notes of {{ .Raw.owner }}\n",
+		// index.ini spells booleans in several ways.
+		"yes/index.ini": "[header]\ncontent = notes.md\ncontent_render = yes\n[properties]\nowner = erin\n",
+		"yes/notes.md":  "- Notes of {{ .Raw.owner }}\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	expected := map[string]string{
+		"flag.md": "generated:: true\nowner:: alice\n\n- Notes of [[alice]] in flag\n",
+		"ext.md":  "generated:: true\nowner:: bob\n\n- Notes of bob\n",
+		"off.md":  "generated:: true\nowner:: carol\n\n- Notes of {{ .Raw.owner }}\n",
+		"copy.md": "generated:: true\nowner:: dave\n\n- Notes of {{ .Raw.owner }}\n",
+		"yes.md":  "generated:: true\nowner:: erin\n\n- Notes of erin\n",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), name)
+	}
+}
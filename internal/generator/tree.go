@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"path"
+	"sort"
+)
+
+// Page summarizes a generated page for navigation in templates.
+type Page struct {
+	// Name is the last segment of the path, e.g. "aaa".
+	Name string
+	// Path is the page path, e.g. "xxx/yyy/aaa".
+	Path string
+	// Namespace is true for generated namespace index pages, which have no
+	// properties of their own.
+	Namespace bool
+	// Keys lists the page property names in output order, like templateData.Keys.
+	Keys       []string
+	Properties map[string]string
+	Raw        map[string]string
+	Values     map[string]interface{}
+}
+
+// site indexes the pages of a build by their position in the asset tree.
+type site struct {
+	pages    []*Page
+	byPath   map[string]*Page
+	byFolder map[string][]*Page
+}
+
+// newSite builds the page tree of the given records. With namespaces, every
+// folder above a record that has no record of its own gets a namespace page.
+func newSite(records []*record, namespaces bool) *site {
+	s := &site{byPath: make(map[string]*Page), byFolder: make(map[string][]*Page)}
+	for _, rec := range records {
+		s.add(&Page{
+			Name:       pageBaseName(rec.path),
+			Path:       rec.path,
+			Keys:       rec.props.Keys(),
+			Properties: rec.props.Transformed(),
+			Raw:        rec.props.Raw(),
+			Values:     rec.props.Values(),
+		})
+	}
+	if namespaces {
+		for _, rec := range records {
+			for folder := path.Dir(rec.path); folder != "." && s.byPath[folder] == nil; folder = path.Dir(folder) {
+				s.add(&Page{
+					Name:       pageBaseName(folder),
+					Path:       folder,
+					Namespace:  true,
+					Properties: map[string]string{},
+					Raw:        map[string]string{},
+					Values:     map[string]interface{}{},
+				})
+			}
+		}
+	}
+
+	sort.Slice(s.pages, func(i, j int) bool { return s.pages[i].Path < s.pages[j].Path })
+	for _, pages := range s.byFolder {
+		sort.Slice(pages, func(i, j int) bool { return pages[i].Path < pages[j].Path })
+	}
+	return s
+}
+
+// add adds a page to the tree.
+func (s *site) add(page *Page) {
+	s.pages = append(s.pages, page)
+	s.byPath[page.Path] = page
+	if page.Path != "." {
+		folder := path.Dir(page.Path)
+		s.byFolder[folder] = append(s.byFolder[folder], page)
+	}
+}
+
+// namespaces returns the generated namespace pages, sorted by path.
+func (s *site) namespaces() []*Page {
+	var pages []*Page
+	for _, page := range s.pages {
+		if page.Namespace {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// pageBaseName returns the last segment of a page path; the root page is
+// called "index".
+func pageBaseName(pagePath string) string {
+	if pagePath == "." {
+		return "index"
+	}
+	return path.Base(pagePath)
+}
+
+// navigation fills in the tree fields of the template data for data.CurrentPath.
+func (s *site) navigation(data *templateData) {
+	current := data.CurrentPath
+	data.Pages = s.pages
+	data.Children = s.byFolder[current]
+
+	if current == "." {
+		data.Root = s.byPath[current]
+		return
+	}
+	folder := path.Dir(current)
+	data.Parent = s.byPath[folder]
+	for _, page := range s.byFolder[folder] {
+		if page.Path != current {
+			data.Siblings = append(data.Siblings, page)
+		}
+	}
+
+	data.Root = s.byPath[current]
+	for p := folder; ; p = path.Dir(p) {
+		if page, ok := s.byPath[p]; ok {
+			data.Root = page
+		}
+		if p == "." {
+			break
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package generator_test
+
+import (
+	"logseq_gen/internal/generator"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Build_TreeNavigation(t *testing.T) {
+	cfg := newTestConfig(t)
+	templateContent := "parent: {{ with .Parent }}{{ .Name }}{{ else }}-{{ end }}\n" +
+		"root: {{ with .Root }}{{ .Path }}{{ end }}\n" +
+		"children:{{ range .Children }} {{ .Name }}={{ .Raw.title }}{{ end }}\n" +
+		"siblings:{{ range .Siblings }} {{ .Name }}{{ end }}\n" +
+		"pages: {{ len .Pages }}\n"
+	writeFiles(t, cfg.TemplateDir, map[string]string{"nav.template": templateContent})
+
+	records := map[string]string{
+		"library":                "Library",
+		"library/books":          "Books",
+		"library/books/dune":     "Dune",
+		"library/books/emma":     "Emma",
+		"library/books/dune/map": "Map of Arrakis",
+		// A folder without an index.ini is skipped when looking up the root.
+		"other/deep/leaf": "Leaf",
+	}
+	for dir, title := range records {
+		writeFiles(t, cfg.AssetsDir, map[string]string{
+			dir + "/index.ini": "[header]\ntemplate = nav\n[properties]\ntitle = " + title + "\n",
+		})
+	}
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	expected := map[string]string{
+		"library.md": "parent: -\nroot: library\nchildren: books=Books\nsiblings:\npages: 6\n",
+		"library___books___dune.md": "parent: books\nroot: library\n" +
+			"children: map=Map of Arrakis\nsiblings: emma\npages: 6\n",
+		"library___books.md":     "parent: library\nroot: library\nchildren: dune=Dune emma=Emma\nsiblings:\npages: 6\n",
+		"other___deep___leaf.md": "parent: -\nroot: other/deep/leaf\nchildren:\nsiblings:\npages: 6\n",
+	}
+	for name, body := range expected {
+		content, err := os.ReadFile(filepath.Join(cfg.PagesDir, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "\n\n"+body, name)
+	}
+}
+
+func TestGenerator_Build_NamespacePages(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.NamespacePages = true
+	writeFiles(t, cfg.AssetsDir, map[string]string{
+		"xxx/yyy/aaa/index.ini": "[header]\ntemplate = nav\n[properties]\nproperty_a = 1\nproperty_b = 2\n",
+		"xxx/yyy/bbb/index.ini": "[properties]\nproperty_a = 3\n",
+		"xxx/zzz/ccc/index.ini": "[properties]\nproperty_a = 4\n",
+		"own/ddd/index.ini":     "[properties]\nproperty_a = 5\n",
+		"own/index.ini":         "[properties]\nproperty_a = 6\n",
+	})
+	writeFiles(t, cfg.TemplateDir, map[string]string{"nav.template": "- Up: {{ pageRef .Parent.Path }}\n"})
+	// A hand-written page is never overwritten.
+	writeFiles(t, cfg.PagesDir, map[string]string{"xxx___zzz.md": "- mine\n"})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "xxx___yyy.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\n\n"+
+		"- [[xxx/yyy/aaa]]\n  property_a:: 1\n  property_b:: 2\n"+
+		"- [[xxx/yyy/bbb]]\n  property_a:: 3\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "xxx.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\n\n- [[xxx/yyy]]\n- [[xxx/zzz]]\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "xxx___zzz.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "- mine\n", string(content))
+
+	// Namespace pages take part in navigation.
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "xxx___yyy___aaa.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- Up: [[xxx/yyy]]\n")
+
+	// Folders with their own index.ini get no namespace page.
+	content, err = os.ReadFile(filepath.Join(cfg.PagesDir, "own.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\nproperty_a:: 6\n\n", string(content))
+}
+
+func TestGenerator_Build_NamespaceTemplate(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.NamespacePages = true
+	cfg.NamespaceTemplate = "namespace"
+	writeFiles(t, cfg.AssetsDir, map[string]string{"books/dune/index.ini": "[properties]\ntitle = Dune\n"})
+	writeFiles(t, cfg.TemplateDir, map[string]string{
+		"namespace.template": "- {{ .CurrentPath }}:{{ range .Children }} {{ .Raw.title }}{{ end }}\n",
+	})
+
+	require.NoError(t, generator.New(cfg).Build())
+
+	content, err := os.ReadFile(filepath.Join(cfg.PagesDir, "books.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "generated:: true\n\n- books: Dune\n", string(content))
+}
@@ -0,0 +1,244 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Env is the build context available to computed properties.
+type Env struct {
+	// CurrentPath is the page path of the record, e.g. "xxx/yyy/aaa".
+	CurrentPath string
+}
+
+// computeData is the data passed to compute expressions.
+type computeData struct {
+	CurrentPath string
+	Properties  map[string]string
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug converts s into a lowercase, dash-separated identifier, e.g.
+// "Hello, World!" becomes "hello-world".
+func Slug(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// computeFuncs are the helpers available in compute expressions.
+var computeFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"trim":    strings.TrimSpace,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"join": func(sep string, values ...string) string {
+		var parts []string
+		for _, v := range values {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+		return strings.Join(parts, sep)
+	},
+	"slug":  Slug,
+	"year":  datePart("2006"),
+	"month": datePart("01"),
+	"day":   datePart("02"),
+	"base": func(path string) string {
+		return path[strings.LastIndex(path, "/")+1:]
+	},
+}
+
+// datePart returns a function that formats a YYYY-MM-DD date with layout.
+func datePart(layout string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		if value == "" {
+			return "", nil
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a valid date in YYYY-MM-DD format", value)
+		}
+		return t.Format(layout), nil
+	}
+}
+
+// parseCompute parses a compute expression.
+func parseCompute(key, expr string) (*template.Template, error) {
+	return template.New(key).Funcs(computeFuncs).Option("missingkey=zero").Parse(expr)
+}
+
+// parseComputes parses the compute expression of every type once. Invalid
+// expressions are left for Lint and Compute to report.
+func parseComputes(types map[string]Type) {
+	for key, typeDef := range types {
+		if typeDef.Compute == "" {
+			continue
+		}
+		if computed, err := parseCompute(key, typeDef.Compute); err == nil {
+			typeDef.computed = computed
+			types[key] = typeDef
+		}
+	}
+}
+
+// computeTemplate returns the parsed compute expression of a property.
+func (typeDef Type) computeTemplate(key string) (*template.Template, error) {
+	if typeDef.computed != nil {
+		return typeDef.computed, nil
+	}
+	tmpl, err := parseCompute(key, typeDef.Compute)
+	if err != nil {
+		return nil, fmt.Errorf("property '%s' has an invalid compute expression: %w", key, err)
+	}
+	return tmpl, nil
+}
+
+// computeOrder returns the computed properties in an order where every
+// property comes after the computed properties its expression refers to.
+// Independent properties are ordered by name.
+func (s *Schema) computeOrder() ([]string, error) {
+	var keys []string
+	deps := make(map[string][]string)
+	for key, typeDef := range s.Types {
+		if typeDef.Compute == "" {
+			continue
+		}
+		keys = append(keys, key)
+		tmpl, err := typeDef.computeTemplate(key)
+		if err != nil {
+			return nil, err
+		}
+		refs := make(map[string]bool)
+		collectPropertyRefs(tmpl.Tree.Root, refs)
+		for ref := range refs {
+			if ref != key && s.Types[ref].Compute != "" {
+				deps[key] = append(deps[key], ref)
+			}
+		}
+		sort.Strings(deps[key])
+	}
+	sort.Strings(keys)
+
+	var order []string
+	state := make(map[string]int) // 1: visiting, 2: done
+	var visit func(key string, chain []string) error
+	visit = func(key string, chain []string) error {
+		switch state[key] {
+		case 1:
+			return fmt.Errorf("computed properties refer to each other: %s", strings.Join(append(chain, key), " -> "))
+		case 2:
+			return nil
+		}
+		state[key] = 1
+		for _, dep := range deps[key] {
+			if err := visit(dep, append(chain, key)); err != nil {
+				return err
+			}
+		}
+		state[key] = 2
+		order = append(order, key)
+		return nil
+	}
+	for _, key := range keys {
+		if err := visit(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// collectPropertyRefs adds the property names an expression reads, either as
+// .Properties.name or as index .Properties "name".
+func collectPropertyRefs(node parse.Node, refs map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectPropertyRefs(child, refs)
+		}
+	case *parse.ActionNode:
+		collectPropertyRefs(n.Pipe, refs)
+	case *parse.IfNode:
+		collectPropertyRefs(n.Pipe, refs)
+		collectPropertyRefs(n.List, refs)
+		collectPropertyRefs(n.ElseList, refs)
+	case *parse.RangeNode:
+		collectPropertyRefs(n.Pipe, refs)
+		collectPropertyRefs(n.List, refs)
+		collectPropertyRefs(n.ElseList, refs)
+	case *parse.WithNode:
+		collectPropertyRefs(n.Pipe, refs)
+		collectPropertyRefs(n.List, refs)
+		collectPropertyRefs(n.ElseList, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectPropertyRefs(cmd, refs)
+		}
+	case *parse.CommandNode:
+		for i, arg := range n.Args {
+			if field, ok := arg.(*parse.FieldNode); ok && i+1 < len(n.Args) && len(field.Ident) == 1 && field.Ident[0] == "Properties" {
+				if str, ok := n.Args[i+1].(*parse.StringNode); ok {
+					refs[str.Text] = true
+				}
+			}
+			collectPropertyRefs(arg, refs)
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) >= 2 && n.Ident[0] == "Properties" {
+			refs[n.Ident[1]] = true
+		}
+	}
+}
+
+// Compute returns a copy of record with every computed property filled in.
+// Expressions see the record's values (with defaults applied) and env.
+// Values already present in the record take precedence over computed ones,
+// and an expression that renders to an empty string leaves the property unset.
+func (s *Schema) Compute(record map[string]string, env Env) (map[string]string, error) {
+	result := make(map[string]string, len(record))
+	for key, value := range record {
+		result[key] = value
+	}
+
+	keys, err := s.computeOrder()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	properties := s.WithDefaults(result)
+	data := computeData{CurrentPath: env.CurrentPath, Properties: properties}
+
+	for _, key := range keys {
+		if _, exists := result[key]; exists {
+			continue
+		}
+		tmpl, err := s.Types[key].computeTemplate(key)
+		if err != nil {
+			return nil, err
+		}
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, data); err != nil {
+			return nil, fmt.Errorf("could not compute property '%s': %w", key, err)
+		}
+		if computed := strings.TrimSpace(value.String()); computed != "" {
+			result[key] = computed
+			properties[key] = computed
+		}
+	}
+	return result, nil
+}
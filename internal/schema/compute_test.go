@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Compute(t *testing.T) {
+	s := &Schema{
+		Name: "person",
+		Types: map[string]Type{
+			"first":     {Type: "string"},
+			"last":      {Type: "string"},
+			"title":     {Type: "string", Default: "Hello, World!"},
+			"born":      {Type: "date"},
+			"full_name": {Type: "string", Compute: `{{ join " " .Properties.first .Properties.last }}`},
+			"slug":      {Type: "string", Compute: `{{ slug .Properties.title }}`},
+			"year":      {Type: "number", Compute: `{{ year .Properties.born }}`},
+			"path":      {Type: "link", Compute: `{{ .CurrentPath }}/{{ base .CurrentPath | upper }}`},
+		},
+	}
+	env := Env{CurrentPath: "people/ada"}
+
+	t.Run("Derives values from properties and context", func(t *testing.T) {
+		record := map[string]string{"first": "Ada", "last": "Lovelace", "born": "1815-12-10"}
+		computed, err := s.Compute(record, env)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada Lovelace", computed["full_name"])
+		assert.Equal(t, "hello-world", computed["slug"]) // from the default title
+		assert.Equal(t, "1815", computed["year"])
+		assert.Equal(t, "people/ada/ADA", computed["path"])
+		assert.NotContains(t, record, "full_name") // input is not modified
+
+		transformed, err := s.ValidateAndTransform(computed)
+		require.NoError(t, err)
+		assert.Equal(t, "1815", transformed["year"])
+	})
+
+	t.Run("Explicit values win and empty results stay unset", func(t *testing.T) {
+		computed, err := s.Compute(map[string]string{"slug": "custom"}, env)
+		require.NoError(t, err)
+		assert.Equal(t, "custom", computed["slug"])
+		assert.NotContains(t, computed, "full_name")
+		assert.NotContains(t, computed, "year")
+	})
+
+	t.Run("Computed values are validated", func(t *testing.T) {
+		computed, err := s.Compute(map[string]string{"born": "tomorrow"}, env)
+		assert.Error(t, err)
+		assert.Nil(t, computed)
+		assert.Contains(t, err.Error(), "could not compute property 'year'")
+		assert.Contains(t, err.Error(), "'tomorrow' is not a valid date in YYYY-MM-DD format")
+	})
+
+	t.Run("Lint catches invalid expressions", func(t *testing.T) {
+		broken := &Schema{Name: "broken", Version: 1, Types: map[string]Type{"x": {Type: "string", Compute: "{{ .Properties.a"}}}
+		issues := broken.Lint()
+		require.Len(t, issues, 1)
+		assert.Contains(t, issues[0].String(), "[ERROR] broken: property 'x': invalid compute expression")
+	})
+
+	t.Run("Computed values may refer to later computed values", func(t *testing.T) {
+		chained := &Schema{Name: "chained", Version: 1, Types: map[string]Type{
+			"a_label": {Type: "string", Compute: `{{ upper (index .Properties "b_name") }}`},
+			"b_name":  {Type: "string", Compute: "{{ .Properties.c_first }} {{ .Properties.last }}"},
+			"c_first": {Type: "string", Compute: "{{ .Properties.first }}"},
+		}}
+		computed, err := chained.Compute(map[string]string{"first": "Ada", "last": "Lovelace"}, env)
+		require.NoError(t, err)
+		assert.Equal(t, "ADA LOVELACE", computed["a_label"])
+		assert.Empty(t, chained.Lint())
+	})
+
+	t.Run("Lint catches cycles", func(t *testing.T) {
+		cyclic := &Schema{Name: "cyclic", Version: 1, Types: map[string]Type{
+			"a": {Type: "string", Compute: "{{ .Properties.b }}"},
+			"b": {Type: "string", Compute: "{{ .Properties.a }}"},
+		}}
+		issues := cyclic.Lint()
+		require.Len(t, issues, 1)
+		assert.Equal(t, "[ERROR] cyclic: computed properties refer to each other: a -> b -> a", issues[0].String())
+
+		_, err := cyclic.Compute(map[string]string{}, env)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadSchema_ParsesComputes(t *testing.T) {
+	dir := t.TempDir()
+	content := "definitions:\n" +
+		"  slugged:\n" +
+		"    type: string\n" +
+		"    compute: '{{ slug .Properties.title }}'\n" +
+		"types:\n" +
+		"  title:\n" +
+		"    type: string\n" +
+		"  slug:\n" +
+		"    ref: slugged\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "note.yaml"), []byte(content), 0644))
+
+	s, err := NewRegistry(dir).Get("note")
+	require.NoError(t, err)
+	require.NotNil(t, s.Types["slug"].computed)
+
+	computed, err := s.Compute(map[string]string{"title": "Hello World"}, Env{})
+	require.NoError(t, err)
+	assert.Equal(t, "hello-world", computed["slug"])
+}
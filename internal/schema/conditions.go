@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StringList is a list of strings that may be written in YAML as either a
+// single scalar or a sequence.
+type StringList []string
+
+// UnmarshalYAML accepts a scalar or a sequence of scalars.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// Condition maps property names to the values that satisfy it. A condition
+// holds when every listed property has one of its values.
+type Condition map[string]StringList
+
+// OneOfGroup is a set of mutually exclusive properties. At most one of them
+// may be set, or exactly one if the group is required.
+type OneOfGroup struct {
+	Properties []string `yaml:"properties" json:"properties"`
+	Required   bool     `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+// UnmarshalYAML accepts either the full mapping or a plain sequence of
+// property names.
+func (g *OneOfGroup) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		g.Required = false
+		return node.Decode(&g.Properties)
+	}
+	type plain OneOfGroup
+	return node.Decode((*plain)(g))
+}
+
+// holds reports whether values satisfy the condition. Comma-separated
+// values (as used by enums) match if any element matches. Enum values are
+// compared by key, so aliases and deprecated keys match their canonical key.
+func (s *Schema) holds(c Condition, values map[string]string) bool {
+	for key, allowed := range c {
+		value, exists := values[key]
+		if !exists {
+			return false
+		}
+		typeDef := s.Types[key]
+		matched := false
+		for _, v := range strings.Split(value, ",") {
+			v = typeDef.canonicalValue(strings.TrimSpace(v))
+			for _, a := range allowed {
+				if v == typeDef.canonicalValue(a) {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalValue returns the enum key a value stands for, or the value itself
+// for other types and unknown keys.
+func (typeDef Type) canonicalValue(value string) string {
+	if typeDef.Type != "enum" {
+		return value
+	}
+	if name, _, ok := typeDef.lookupEnum(value); ok {
+		return name
+	}
+	return value
+}
+
+// keys returns the condition's property names in sorted order.
+func (c Condition) keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String describes the condition for error messages, e.g. "status is 'a' or 'b'".
+func (c Condition) String() string {
+	var parts []string
+	for _, key := range c.keys() {
+		quoted := make([]string, 0, len(c[key]))
+		for _, v := range c[key] {
+			quoted = append(quoted, fmt.Sprintf("'%s'", v))
+		}
+		parts = append(parts, fmt.Sprintf("%s is %s", key, strings.Join(quoted, " or ")))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// checkConditions evaluates required_if, forbidden_unless and one_of rules.
+// Conditions compare the values of the record with defaults applied. A
+// default satisfies required_if, like required, but forbidden_unless and
+// one_of only look at the properties the record sets itself.
+func (s *Schema) checkConditions(record map[string]string) error {
+	values := s.WithDefaults(record)
+
+	keys := make([]string, 0, len(s.Types))
+	for key := range s.Types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		typeDef := s.Types[key]
+		_, hasValue := values[key]
+		if !hasValue && len(typeDef.RequiredIf) > 0 && s.holds(typeDef.RequiredIf, values) {
+			return fmt.Errorf("property '%s' is required when %s", key, typeDef.RequiredIf)
+		}
+		if _, set := record[key]; set && len(typeDef.ForbiddenUnless) > 0 && !s.holds(typeDef.ForbiddenUnless, values) {
+			return fmt.Errorf("property '%s' is only allowed when %s", key, typeDef.ForbiddenUnless)
+		}
+	}
+
+	for _, group := range s.OneOf {
+		var present []string
+		for _, key := range group.Properties {
+			if _, exists := record[key]; exists {
+				present = append(present, key)
+			}
+		}
+		names := "'" + strings.Join(group.Properties, "', '") + "'"
+		if len(present) > 1 {
+			return fmt.Errorf("only one of %s may be set, found '%s'", names, strings.Join(present, "', '"))
+		}
+		if group.Required && len(present) == 0 {
+			return fmt.Errorf("one of %s is required", names)
+		}
+	}
+	return nil
+}
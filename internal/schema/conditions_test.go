@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_Conditions(t *testing.T) {
+	schemaContent := `
+version: 1
+types:
+  status:
+    type: enum
+    default: todo
+    keys:
+      todo: {}
+      in_progress: {}
+      blocked: {aliases: [stuck]}
+      cancelled: {}
+  due_date:
+    type: date
+    required_if:
+      status: [in_progress, blocked]
+  reason:
+    type: string
+    forbidden_unless:
+      status: cancelled
+  resolution:
+    type: string
+    default: none
+    forbidden_unless:
+      status: cancelled
+  isbn:
+    type: string
+  url:
+    type: string
+  owner:
+    type: string
+  team:
+    type: string
+one_of:
+  - [isbn, url]
+  - properties: [owner, team]
+    required: true
+`
+	var s Schema
+	require.NoError(t, yaml.Unmarshal([]byte(schemaContent), &s))
+	assert.Equal(t, Condition{"status": {"cancelled"}}, s.Types["reason"].ForbiddenUnless)
+	assert.Equal(t, []OneOfGroup{{Properties: []string{"isbn", "url"}}, {Properties: []string{"owner", "team"}, Required: true}}, s.OneOf)
+
+	tests := []struct {
+		name   string
+		record map[string]string
+		err    string
+	}{
+		{"Defaults satisfy every rule", map[string]string{"owner": "me"}, ""},
+		{"Required when condition holds", map[string]string{"owner": "me", "status": "blocked"}, "property 'due_date' is required when status is 'in_progress' or 'blocked'"},
+		{"Required field present", map[string]string{"owner": "me", "status": "in_progress", "due_date": "2025-01-01"}, ""},
+		{"Forbidden unless condition holds", map[string]string{"owner": "me", "reason": "dup"}, "property 'reason' is only allowed when status is 'cancelled'"},
+		{"Aliases match their key", map[string]string{"owner": "me", "status": "stuck"}, "property 'due_date' is required when status is 'in_progress' or 'blocked'"},
+		{"Forbidden property set explicitly", map[string]string{"owner": "me", "resolution": "fixed"}, "property 'resolution' is only allowed when status is 'cancelled'"},
+		{"Allowed when condition holds", map[string]string{"owner": "me", "status": "cancelled", "reason": "dup"}, ""},
+		{"Mutually exclusive", map[string]string{"owner": "me", "isbn": "1", "url": "x"}, "only one of 'isbn', 'url' may be set, found 'isbn', 'url'"},
+		{"Required group", map[string]string{}, "one of 'owner', 'team' is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ValidateAndTransform(tt.record)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("Lint flags undeclared references", func(t *testing.T) {
+		broken := &Schema{
+			Name:    "broken",
+			Version: 1,
+			Types:   map[string]Type{"a": {Type: "string", RequiredIf: Condition{"stauts": {"x"}}}},
+			OneOf:   []OneOfGroup{{Properties: []string{"a"}}},
+		}
+		var messages []string
+		for _, issue := range broken.Lint() {
+			messages = append(messages, issue.String())
+		}
+		assert.Equal(t, []string{
+			"[WARN] broken: property 'a': condition refers to undeclared property 'stauts'",
+			"[WARN] broken: one_of group 1 lists fewer than two properties",
+		}, messages)
+	})
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Context holds validation state that spans every record in a build.
+type Context struct {
+	// seen maps schema name -> property -> value -> path of the record that claimed it.
+	seen map[string]map[string]map[string]string
+}
+
+// NewContext creates an empty validation context.
+func NewContext() *Context {
+	return &Context{seen: make(map[string]map[string]map[string]string)}
+}
+
+// CheckUnique verifies that the unique properties of a record have not already
+// been claimed by another record using the same schema. If the record is
+// accepted, its values are registered under path.
+func (c *Context) CheckUnique(s *Schema, path string, record map[string]string) error {
+	bySchema, ok := c.seen[s.Name]
+	if !ok {
+		bySchema = make(map[string]map[string]string)
+		c.seen[s.Name] = bySchema
+	}
+
+	var keys []string
+	for key, typeDef := range s.Types {
+		if typeDef.Unique {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var duplicates []string
+	for _, key := range keys {
+		value, exists := record[key]
+		if !exists {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if other, ok := bySchema[key][value]; ok && other != path {
+			duplicates = append(duplicates, fmt.Sprintf("unique property '%s' with value '%s' in %s is already used by %s", key, value, path, other))
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%s", strings.Join(duplicates, "; "))
+	}
+
+	for _, key := range keys {
+		value, exists := record[key]
+		if !exists {
+			continue
+		}
+		if bySchema[key] == nil {
+			bySchema[key] = make(map[string]string)
+		}
+		bySchema[key][strings.TrimSpace(value)] = path
+	}
+	return nil
+}
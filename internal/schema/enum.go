@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// NamespaceProperty prefixes enum pages with the property name. It is the default.
+	NamespaceProperty = "property"
+	// NamespaceNone emits enum pages without a namespace.
+	NamespaceNone = "none"
+
+	// FormatLink emits enum values as page links, e.g. [[status/Open]]. It is the default.
+	FormatLink = "link"
+	// FormatTag emits enum values as tags, e.g. #[[status/Open]].
+	FormatTag = "tag"
+)
+
+// lookupEnum resolves an input value to its enum key, accepting aliases and
+// following deprecated keys to their replacement.
+func (typeDef Type) lookupEnum(value string) (string, EnumKey, bool) {
+	name, ok := value, false
+	if _, ok = typeDef.Keys[value]; !ok {
+		name, ok = typeDef.aliasFor(value)
+	}
+	if !ok {
+		return "", EnumKey{}, false
+	}
+
+	seen := map[string]bool{name: true}
+	enumKey := typeDef.Keys[name]
+	for enumKey.Deprecated && enumKey.ReplacedBy != "" {
+		replacement, exists := typeDef.Keys[enumKey.ReplacedBy]
+		if !exists || seen[enumKey.ReplacedBy] {
+			break
+		}
+		name = enumKey.ReplacedBy
+		seen[name] = true
+		enumKey = replacement
+	}
+	return name, enumKey, true
+}
+
+// aliasFor returns the key that lists value as an alias.
+func (typeDef Type) aliasFor(value string) (string, bool) {
+	for name, enumKey := range typeDef.Keys {
+		for _, alias := range enumKey.Aliases {
+			if alias == value {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// enumReference renders a resolved enum key as a Logseq page link or tag.
+func (typeDef Type) enumReference(property, name string, enumKey EnumKey) string {
+	page := typeDef.enumPage(property, name, enumKey)
+	if typeDef.Format == FormatTag {
+		return fmt.Sprintf("#[[%s]]", page)
+	}
+	return fmt.Sprintf("[[%s]]", page)
+}
+
+// EnumPage returns the name of the Logseq page that the given key of an enum
+// property links to.
+func (typeDef Type) EnumPage(property, key string) string {
+	return typeDef.enumPage(property, key, typeDef.Keys[key])
+}
+
+// enumPage returns the name of the Logseq page an enum key refers to.
+func (typeDef Type) enumPage(property, name string, enumKey EnumKey) string {
+	display := typeDef.enumDisplay(name, enumKey)
+	switch typeDef.Namespace {
+	case "", NamespaceProperty:
+		return property + "/" + display
+	case NamespaceNone:
+		return display
+	default:
+		return strings.TrimSuffix(typeDef.Namespace, "/") + "/" + display
+	}
+}
+
+// enumDisplay returns the display name of an enum key, falling back to the
+// key itself or its humanized form.
+func (typeDef Type) enumDisplay(name string, enumKey EnumKey) string {
+	if enumKey.Display != "" {
+		return enumKey.Display
+	}
+	if typeDef.Humanize {
+		return humanize(name)
+	}
+	return name
+}
+
+// humanize turns an identifier such as "num_4" into "Num 4".
+func humanize(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// Warnings returns non-fatal messages about a record, such as the use of
+// deprecated enum keys.
+func (s *Schema) Warnings(record map[string]string) []string {
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	for _, key := range keys {
+		typeDef, ok := s.Types[key]
+		if !ok || typeDef.Type != "enum" {
+			continue
+		}
+		for _, v := range strings.Split(record[key], ",") {
+			value := strings.TrimSpace(v)
+			name := value
+			if _, exists := typeDef.Keys[name]; !exists {
+				if name, ok = typeDef.aliasFor(value); !ok {
+					continue
+				}
+			}
+			enumKey := typeDef.Keys[name]
+			if !enumKey.Deprecated {
+				continue
+			}
+			message := fmt.Sprintf("property '%s' uses deprecated enum key '%s'", key, name)
+			if enumKey.ReplacedBy != "" {
+				message += fmt.Sprintf("; use '%s' instead", enumKey.ReplacedBy)
+			}
+			warnings = append(warnings, message)
+		}
+	}
+	return warnings
+}
+
+// lintEnum checks the enum-specific settings of a type.
+func (typeDef Type) lintEnum(add func(severity Severity, format string, args ...interface{})) {
+	if typeDef.Format != "" && typeDef.Format != FormatLink && typeDef.Format != FormatTag {
+		add(SeverityError, "unknown enum format '%s'", typeDef.Format)
+	}
+
+	names := make([]string, 0, len(typeDef.Keys))
+	for name := range typeDef.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	owners := make(map[string]string)
+	for _, name := range names {
+		owners[name] = name
+	}
+	for _, name := range names {
+		enumKey := typeDef.Keys[name]
+		for _, alias := range enumKey.Aliases {
+			if owner, exists := owners[alias]; exists && owner != name {
+				add(SeverityError, "alias '%s' of key '%s' conflicts with '%s'", alias, name, owner)
+				continue
+			}
+			owners[alias] = name
+		}
+		if enumKey.ReplacedBy != "" {
+			if _, exists := typeDef.Keys[enumKey.ReplacedBy]; !exists {
+				add(SeverityError, "key '%s' is replaced by unknown key '%s'", name, enumKey.ReplacedBy)
+			}
+			if !enumKey.Deprecated {
+				add(SeverityWarning, "key '%s' has replaced_by but is not deprecated", name)
+			}
+		}
+	}
+}
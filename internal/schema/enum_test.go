@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_Enum(t *testing.T) {
+	schemaContent := `
+version: 1
+types:
+  status:
+    type: enum
+    keys:
+      open:
+        display: Open
+        aliases: [opened, active]
+      wip:
+        deprecated: true
+        replaced_by: in_progress
+      in_progress:
+        display: In Progress
+      legacy:
+        deprecated: true
+  priority:
+    type: enum
+    namespace: none
+    format: tag
+    keys:
+      p1:
+        display: Urgent
+  size:
+    type: enum
+    namespace: sizes/
+    humanize: true
+    keys:
+      extra_large: {}
+`
+	var s Schema
+	require.NoError(t, yaml.Unmarshal([]byte(schemaContent), &s))
+
+	t.Run("Aliases and replacements", func(t *testing.T) {
+		transformed, err := s.ValidateAndTransform(map[string]string{"status": "active, wip, legacy"})
+		require.NoError(t, err)
+		assert.Equal(t, "[[status/Open]] [[status/In Progress]] [[status/legacy]]", transformed["status"])
+	})
+
+	t.Run("Namespace, tag format and humanized names", func(t *testing.T) {
+		transformed, err := s.ValidateAndTransform(map[string]string{"priority": "p1", "size": "extra_large"})
+		require.NoError(t, err)
+		assert.Equal(t, "#[[Urgent]]", transformed["priority"])
+		assert.Equal(t, "[[sizes/Extra Large]]", transformed["size"])
+	})
+
+	t.Run("Deprecation warnings", func(t *testing.T) {
+		warnings := s.Warnings(map[string]string{"status": "open, wip, legacy", "priority": "p1"})
+		assert.Equal(t, []string{
+			"property 'status' uses deprecated enum key 'wip'; use 'in_progress' instead",
+			"property 'status' uses deprecated enum key 'legacy'",
+		}, warnings)
+	})
+
+	t.Run("Lint", func(t *testing.T) {
+		broken := &Schema{
+			Name:    "broken",
+			Version: 1,
+			Types: map[string]Type{
+				"e": {Type: "enum", Format: "badge", Keys: map[string]EnumKey{
+					"a": {Aliases: []string{"b"}},
+					"b": {ReplacedBy: "zzz"},
+				}},
+			},
+		}
+		var messages []string
+		for _, issue := range broken.Lint() {
+			messages = append(messages, issue.String())
+		}
+		assert.Equal(t, []string{
+			"[ERROR] broken: property 'e': unknown enum format 'badge'",
+			"[ERROR] broken: property 'e': alias 'b' of key 'a' conflicts with 'b'",
+			"[ERROR] broken: property 'e': key 'b' is replaced by unknown key 'zzz'",
+			"[WARN] broken: property 'e': key 'b' has replaced_by but is not deprecated",
+		}, messages)
+	})
+}
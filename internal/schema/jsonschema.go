@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// jsonSchemaDialect is the JSON Schema draft used for exported documents.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of a JSON Schema document that maps onto a Schema.
+// Rules JSON Schema has no keyword for are kept in "x-" extensions, so that a
+// round trip does not loosen the schema.
+type jsonSchema struct {
+	Dialect              string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Examples             []interface{}          `json:"examples,omitempty"`
+	Version              int                    `json:"x-version,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	EnumDisplay          map[string]string      `json:"x-enum-display,omitempty"`
+	EnumKeys             map[string]EnumKey     `json:"x-enum-keys,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+
+	NativeType      string       `json:"x-type,omitempty"`
+	LinkSchema      string       `json:"x-schema,omitempty"`
+	Unique          bool         `json:"x-unique,omitempty"`
+	Compute         string       `json:"x-compute,omitempty"`
+	Namespace       string       `json:"x-namespace,omitempty"`
+	DisplayFormat   string       `json:"x-format,omitempty"`
+	Humanize        bool         `json:"x-humanize,omitempty"`
+	RequiredIf      Condition    `json:"x-required-if,omitempty"`
+	ForbiddenUnless Condition    `json:"x-forbidden-unless,omitempty"`
+	OneOf           []OneOfGroup `json:"x-one-of,omitempty"`
+	Migrations      []Migration  `json:"x-migrations,omitempty"`
+}
+
+// ExportJSONSchema converts the schema to a JSON Schema document describing
+// the records it accepts.
+func (s *Schema) ExportJSONSchema() ([]byte, error) {
+	doc := jsonSchema{
+		Dialect:    jsonSchemaDialect,
+		Title:      s.Name,
+		Version:    s.Version,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+		OneOf:      s.OneOf,
+		Migrations: s.Migrations,
+	}
+	if s.IsStrict() {
+		doc.AdditionalProperties = false
+	}
+
+	for key, typeDef := range s.Types {
+		property, err := exportType(typeDef)
+		if err != nil {
+			return nil, fmt.Errorf("property '%s': %w", key, err)
+		}
+		doc.Properties[key] = property
+		if typeDef.Required && typeDef.Default == nil {
+			doc.Required = append(doc.Required, key)
+		}
+	}
+	sort.Strings(doc.Required)
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func exportType(typeDef Type) (*jsonSchema, error) {
+	property := &jsonSchema{
+		Title:           typeDef.Label,
+		Description:     typeDef.Description,
+		Default:         typeDef.Default,
+		Pattern:         typeDef.Pattern,
+		LinkSchema:      typeDef.Schema,
+		Unique:          typeDef.Unique,
+		Compute:         typeDef.Compute,
+		Namespace:       typeDef.Namespace,
+		DisplayFormat:   typeDef.Format,
+		Humanize:        typeDef.Humanize,
+		RequiredIf:      typeDef.RequiredIf,
+		ForbiddenUnless: typeDef.ForbiddenUnless,
+	}
+	if typeDef.Example != nil {
+		property.Examples = []interface{}{typeDef.Example}
+	}
+
+	switch typeDef.Type {
+	case "string":
+		property.Type = "string"
+	case "link":
+		property.Type = "string"
+		property.NativeType = "link"
+	case "number":
+		property.Type = "number"
+		property.Minimum = typeDef.Minimum
+		property.Maximum = typeDef.Maximum
+	case "boolean":
+		property.Type = "boolean"
+	case "date":
+		property.Type = "string"
+		property.Format = "date"
+	case "enum":
+		property.Type = "string"
+		keys := make([]string, 0, len(typeDef.Keys))
+		for key := range typeDef.Keys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property.Enum = append(property.Enum, key)
+			enumKey := typeDef.Keys[key]
+			if enumKey.Display != "" {
+				if property.EnumDisplay == nil {
+					property.EnumDisplay = make(map[string]string)
+				}
+				property.EnumDisplay[key] = enumKey.Display
+			}
+			enumKey.Display = ""
+			if enumKey.Description != "" || len(enumKey.Aliases) > 0 || enumKey.Deprecated || enumKey.ReplacedBy != "" {
+				if property.EnumKeys == nil {
+					property.EnumKeys = make(map[string]EnumKey)
+				}
+				property.EnumKeys[key] = enumKey
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", typeDef.Type)
+	}
+	return property, nil
+}
+
+// ImportJSONSchema converts a JSON Schema document into a Schema. Only flat
+// objects are supported, with properties using type, format, enum, default,
+// pattern, minimum, maximum, title, description, examples and the "x-"
+// extensions written by ExportJSONSchema.
+func ImportJSONSchema(data []byte) (*Schema, error) {
+	var doc jsonSchema
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if t, ok := doc.Type.(string); doc.Type != nil && (!ok || t != "object") {
+		return nil, fmt.Errorf("top-level type must be 'object'")
+	}
+
+	s := &Schema{
+		Name:       doc.Title,
+		Version:    doc.Version,
+		Types:      make(map[string]Type),
+		OneOf:      doc.OneOf,
+		Migrations: doc.Migrations,
+	}
+	if s.Version == 0 {
+		s.Version = 1
+	}
+	if allowed, ok := doc.AdditionalProperties.(bool); ok && !allowed {
+		s.AdditionalProperties = &allowed
+	}
+
+	required := make(map[string]bool)
+	for _, key := range doc.Required {
+		required[key] = true
+	}
+
+	for key, property := range doc.Properties {
+		typeDef, err := importType(property)
+		if err != nil {
+			return nil, fmt.Errorf("property '%s': %w", key, err)
+		}
+		typeDef.Required = required[key]
+		s.Types[key] = typeDef
+	}
+	for key := range required {
+		if _, ok := s.Types[key]; !ok {
+			return nil, fmt.Errorf("required property '%s' is not defined", key)
+		}
+	}
+
+	return s, nil
+}
+
+func importType(property *jsonSchema) (Type, error) {
+	typeDef := Type{
+		Label:           property.Title,
+		Description:     property.Description,
+		Default:         property.Default,
+		Pattern:         property.Pattern,
+		Minimum:         property.Minimum,
+		Maximum:         property.Maximum,
+		Schema:          property.LinkSchema,
+		Unique:          property.Unique,
+		Compute:         property.Compute,
+		Namespace:       property.Namespace,
+		Format:          property.DisplayFormat,
+		Humanize:        property.Humanize,
+		RequiredIf:      property.RequiredIf,
+		ForbiddenUnless: property.ForbiddenUnless,
+	}
+	if len(property.Examples) > 0 {
+		typeDef.Example = property.Examples[0]
+	}
+
+	jsonType, ok := property.Type.(string)
+	if property.Type != nil && !ok {
+		return Type{}, fmt.Errorf("only a single type is supported")
+	}
+
+	switch {
+	case len(property.Enum) > 0:
+		typeDef.Type = "enum"
+		typeDef.Keys = make(map[string]EnumKey)
+		for _, value := range property.Enum {
+			key := fmt.Sprintf("%v", value)
+			enumKey := property.EnumKeys[key]
+			enumKey.Display = property.EnumDisplay[key]
+			typeDef.Keys[key] = enumKey
+		}
+	case property.NativeType == "link":
+		typeDef.Type = "link"
+	case jsonType == "string" && property.Format == "date":
+		typeDef.Type = "date"
+	case jsonType == "string":
+		typeDef.Type = "string"
+	case jsonType == "number" || jsonType == "integer":
+		typeDef.Type = "number"
+	case jsonType == "boolean":
+		typeDef.Type = "boolean"
+	case jsonType == "":
+		return Type{}, fmt.Errorf("missing type")
+	default:
+		return Type{}, fmt.Errorf("unsupported type '%s'", jsonType)
+	}
+	return typeDef, nil
+}
+
+// isJSONSchema reports whether data looks like a JSON Schema document rather
+// than a native schema written in JSON.
+func isJSONSchema(data []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	_, hasDialect := probe["$schema"]
+	_, hasProperties := probe["properties"]
+	return hasDialect || hasProperties
+}
@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_ExportJSONSchema(t *testing.T) {
+	minimum := 0.0
+	disallow := false
+	s := &Schema{
+		Name:                 "book",
+		Version:              2,
+		AdditionalProperties: &disallow,
+		Types: map[string]Type{
+			"title":  {Type: "string", Required: true},
+			"pages":  {Type: "number", Minimum: &minimum},
+			"read":   {Type: "boolean", Required: true, Default: false},
+			"isbn":   {Type: "string", Pattern: `^\d{13}$`},
+			"added":  {Type: "date"},
+			"status": {Type: "enum", Keys: map[string]EnumKey{"open": {Display: "Open"}, "done": {}}},
+		},
+	}
+
+	data, err := s.ExportJSONSchema()
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, jsonSchemaDialect, doc["$schema"])
+	assert.Equal(t, "book", doc["title"])
+	assert.Equal(t, "object", doc["type"])
+	assert.Equal(t, false, doc["additionalProperties"])
+	assert.Equal(t, []interface{}{"title"}, doc["required"]) // defaults make "read" optional
+
+	properties := doc["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "number", "minimum": 0.0}, properties["pages"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date"}, properties["added"])
+	assert.Equal(t, `^\d{13}$`, properties["isbn"].(map[string]interface{})["pattern"])
+	assert.Equal(t, []interface{}{"done", "open"}, properties["status"].(map[string]interface{})["enum"])
+
+	t.Run("Round trip", func(t *testing.T) {
+		imported, err := ImportJSONSchema(data)
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported.Version)
+		assert.True(t, imported.IsStrict())
+		assert.Equal(t, s.Types["pages"], imported.Types["pages"])
+		assert.Equal(t, s.Types["added"], imported.Types["added"])
+		assert.Equal(t, s.Types["status"], imported.Types["status"])
+		assert.True(t, imported.Types["title"].Required)
+	})
+
+	t.Run("Round trip keeps native rules as extensions", func(t *testing.T) {
+		native := &Schema{
+			Name:    "loan",
+			Version: 2,
+			Types: map[string]Type{
+				"status": {Type: "enum", Label: "Status", Keys: map[string]EnumKey{
+					"out": {Display: "Lent out", Aliases: []string{"lent"}},
+					"old": {Deprecated: true, ReplacedBy: "out"},
+				}},
+				"borrower": {Type: "link", Schema: "person", RequiredIf: Condition{"status": {"out"}}},
+				"returned": {Type: "date", ForbiddenUnless: Condition{"status": {"old"}}},
+				"code":     {Type: "string", Unique: true, Compute: "{{ .Raw.status }}"},
+			},
+			OneOf:      []OneOfGroup{{Properties: []string{"borrower", "returned"}}},
+			Migrations: []Migration{{From: 1, Steps: []MigrationStep{{Rename: &RenameStep{From: "lender", To: "borrower"}}}}},
+		}
+		data, err := native.ExportJSONSchema()
+		require.NoError(t, err)
+
+		imported, err := ImportJSONSchema(data)
+		require.NoError(t, err)
+		assert.Equal(t, native.Types, imported.Types)
+		assert.Equal(t, native.OneOf, imported.OneOf)
+		assert.Equal(t, native.Migrations, imported.Migrations)
+
+		transformed, err := imported.ValidateAndTransform(map[string]string{"status": "lent", "borrower": "Ada"})
+		require.NoError(t, err)
+		assert.Equal(t, "[[status/Lent out]]", transformed["status"])
+		_, err = imported.ValidateAndTransform(map[string]string{"status": "out"})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadSchema_CompilesPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "code.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("types:\n  code:\n    type: string\n    pattern: '^[A-Z]+$'\n"), 0644))
+	s, err := LoadSchema(path)
+	require.NoError(t, err)
+	require.NotNil(t, s.Types["code"].compiled)
+	assert.True(t, s.Types["code"].compiled.MatchString("ABC"))
+}
+
+func TestImportJSONSchema(t *testing.T) {
+	t.Run("Supported subset", func(t *testing.T) {
+		s, err := ImportJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {
+				"count": {"type": "integer", "minimum": 1, "maximum": 10, "default": 1},
+				"code": {"type": "string", "pattern": "^[A-Z]+$"},
+				"color": {"enum": ["red", "green"]}
+			},
+			"required": ["code"]
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.Version)
+		assert.Equal(t, "number", s.Types["count"].Type)
+		assert.Equal(t, 10.0, *s.Types["count"].Maximum)
+		assert.True(t, s.Types["code"].Required)
+		assert.Equal(t, "enum", s.Types["color"].Type)
+		assert.Contains(t, s.Types["color"].Keys, "green")
+
+		_, err = s.ValidateAndTransform(map[string]string{"code": "abc"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "property 'code' with value 'abc' does not match pattern '^[A-Z]+$'")
+
+		_, err = s.ValidateAndTransform(map[string]string{"code": "ABC", "count": "11"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "property 'count' with value '11' is greater than the maximum 10")
+
+		_, err = s.ValidateAndTransform(map[string]string{"code": "ABC", "count": "0"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "property 'count' with value '0' is less than the minimum 1")
+	})
+
+	t.Run("Unsupported types", func(t *testing.T) {
+		_, err := ImportJSONSchema([]byte(`{"properties": {"tags": {"type": "array"}}}`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "property 'tags': unsupported type 'array'")
+	})
+
+	t.Run("LoadSchema reads JSON Schema files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "thing.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"$schema": "`+jsonSchemaDialect+`", "properties": {"when": {"type": "string", "format": "date"}}}`), 0644))
+		s, err := LoadSchema(path)
+		require.NoError(t, err)
+		assert.Equal(t, "thing", s.Name)
+		assert.Equal(t, "date", s.Types["when"].Type)
+	})
+
+	t.Run("LoadSchema still reads native JSON schemas", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "native.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "types": {"when": {"type": "date"}}}`), 0644))
+		s, err := LoadSchema(path)
+		require.NoError(t, err)
+		assert.Equal(t, "date", s.Types["when"].Type)
+	})
+}
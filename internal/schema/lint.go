@@ -0,0 +1,245 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity describes how serious a lint issue is.
+type Severity int
+
+const (
+	// SeverityWarning marks a likely mistake that does not prevent validation.
+	SeverityWarning Severity = iota
+	// SeverityError marks a schema that cannot be used.
+	SeverityError
+)
+
+// String returns the label used when printing issues.
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "ERROR"
+	}
+	return "WARN"
+}
+
+// Issue is a single problem found in a schema definition.
+type Issue struct {
+	Schema   string
+	Property string
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue for logs.
+func (i Issue) String() string {
+	if i.Property == "" {
+		return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Schema, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: property '%s': %s", i.Severity, i.Schema, i.Property, i.Message)
+}
+
+// knownTypes lists the property types understood by ValidateAndTransform.
+var knownTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"boolean": true,
+	"enum":    true,
+	"link":    true,
+	"date":    true,
+}
+
+// HasErrors reports whether any of the issues is an error.
+func HasErrors(issues []Issue) bool {
+	errors, _ := CountIssues(issues)
+	return errors > 0
+}
+
+// CountIssues returns the number of errors and warnings among issues.
+func CountIssues(issues []Issue) (errors, warnings int) {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// Lint checks the schema definition itself for mistakes, independently of
+// any record.
+func (s *Schema) Lint() []Issue {
+	var issues []Issue
+	add := func(property string, severity Severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Schema: s.Name, Property: property, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Version < 0 || s.Version == 0 && s.versionSet {
+		add("", SeverityError, "unsupported version %d; versions must be positive integers", s.Version)
+	} else if s.Version == 0 {
+		add("", SeverityWarning, "version is not set")
+	}
+
+	computeInvalid := false
+	keys := make([]string, 0, len(s.Types))
+	for key := range s.Types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		typeDef := s.Types[key]
+
+		if !knownTypes[typeDef.Type] {
+			if typeDef.Type == "" {
+				add(key, SeverityError, "type is not set")
+			} else {
+				add(key, SeverityError, "unknown type '%s'", typeDef.Type)
+			}
+			continue
+		}
+
+		if typeDef.Type == "enum" && len(typeDef.Keys) == 0 {
+			add(key, SeverityError, "enum has no keys")
+		}
+		if typeDef.Type == "enum" {
+			typeDef.lintEnum(func(severity Severity, format string, args ...interface{}) {
+				add(key, severity, format, args...)
+			})
+		}
+		if typeDef.Type != "enum" && len(typeDef.Keys) > 0 {
+			add(key, SeverityWarning, "keys are only used by enum types")
+		}
+
+		if typeDef.Pattern != "" {
+			if _, err := regexp.Compile(typeDef.Pattern); err != nil {
+				add(key, SeverityError, "invalid pattern '%s': %v", typeDef.Pattern, err)
+				continue
+			}
+		}
+		if typeDef.Compute != "" {
+			if _, err := parseCompute(key, typeDef.Compute); err != nil {
+				add(key, SeverityError, "invalid compute expression: %v", err)
+				computeInvalid = true
+			}
+		}
+		if typeDef.Minimum != nil && typeDef.Maximum != nil && *typeDef.Minimum > *typeDef.Maximum {
+			add(key, SeverityError, "minimum %v is greater than maximum %v", *typeDef.Minimum, *typeDef.Maximum)
+		}
+
+		for _, condition := range []Condition{typeDef.RequiredIf, typeDef.ForbiddenUnless} {
+			for _, ref := range condition.keys() {
+				if _, ok := s.Types[ref]; !ok {
+					add(key, SeverityWarning, "condition refers to undeclared property '%s'", ref)
+				}
+			}
+		}
+
+		if typeDef.Example != nil {
+			if _, err := typeDef.transform(key, fmt.Sprintf("%v", typeDef.Example)); err != nil {
+				add(key, SeverityWarning, "example does not satisfy its own type: %v", err)
+			}
+		}
+
+		if typeDef.Default != nil {
+			if typeDef.Required {
+				add(key, SeverityWarning, "required has no effect when a default is set")
+			}
+			if _, err := typeDef.transform(key, fmt.Sprintf("%v", typeDef.Default)); err != nil {
+				add(key, SeverityError, "default does not satisfy its own type: %v", err)
+			}
+		}
+	}
+
+	if !computeInvalid {
+		if _, err := s.computeOrder(); err != nil {
+			add("", SeverityError, "%v", err)
+		}
+	}
+
+	for i, group := range s.OneOf {
+		if len(group.Properties) < 2 {
+			add("", SeverityWarning, "one_of group %d lists fewer than two properties", i+1)
+		}
+		for _, ref := range group.Properties {
+			if _, ok := s.Types[ref]; !ok {
+				add("", SeverityWarning, "one_of group %d refers to undeclared property '%s'", i+1, ref)
+			}
+		}
+	}
+
+	return issues
+}
+
+// Names lists the schemas available in the registry's directory.
+func (r *Registry) Names() ([]string, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".json") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Lint resolves the named schema and checks it, including references to
+// other schema files.
+func (r *Registry) Lint(name string) []Issue {
+	s, err := r.Get(name)
+	if err != nil {
+		return []Issue{{Schema: name, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	issues := s.Lint()
+
+	keys := make([]string, 0, len(s.Types))
+	for key := range s.Types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ref := s.Types[key].Schema
+		if ref == "" {
+			continue
+		}
+		if _, err := os.Stat(r.Path(ref)); err != nil {
+			issues = append(issues, Issue{Schema: name, Property: key, Severity: SeverityError, Message: fmt.Sprintf("referenced schema '%s' does not exist", ref)})
+		}
+	}
+	return issues
+}
+
+// LintAll lints every schema in the registry's directory.
+func (r *Registry) LintAll() ([]Issue, error) {
+	names, err := r.Names()
+	if err != nil {
+		return nil, fmt.Errorf("could not list schemas in %s: %w", r.dir, err)
+	}
+
+	var issues []Issue
+	for _, name := range names {
+		issues = append(issues, r.Lint(name)...)
+	}
+	return issues, nil
+}
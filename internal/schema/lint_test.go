@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Lint(t *testing.T) {
+	minimum, maximum := 10.0, 1.0
+	s := &Schema{
+		Name:    "broken",
+		Version: 1,
+		Types: map[string]Type{
+			"a_unknown":  {Type: "nubmer"},
+			"b_enum":     {Type: "enum"},
+			"c_default":  {Type: "number", Default: "many"},
+			"d_required": {Type: "boolean", Required: true, Default: false},
+			"e_pattern":  {Type: "string", Pattern: "("},
+			"f_bounds":   {Type: "number", Minimum: &minimum, Maximum: &maximum},
+			"g_fine":     {Type: "enum", Keys: map[string]EnumKey{"x": {}}, Default: "x"},
+		},
+	}
+
+	var messages []string
+	for _, issue := range s.Lint() {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		"[ERROR] broken: property 'a_unknown': unknown type 'nubmer'",
+		"[ERROR] broken: property 'b_enum': enum has no keys",
+		"[ERROR] broken: property 'c_default': default does not satisfy its own type: property 'c_default' with value 'many' is not a valid number",
+		"[WARN] broken: property 'd_required': required has no effect when a default is set",
+		"[ERROR] broken: property 'e_pattern': invalid pattern '(': error parsing regexp: missing closing ): `(`",
+		"[ERROR] broken: property 'f_bounds': minimum 10 is greater than maximum 1",
+	}, messages)
+
+	t.Run("Version", func(t *testing.T) {
+		issues := (&Schema{Name: "v", Version: -1}).Lint()
+		require.Len(t, issues, 1)
+		assert.Equal(t, "[ERROR] v: unsupported version -1; versions must be positive integers", issues[0].String())
+
+		issues = (&Schema{Name: "v"}).Lint()
+		require.Len(t, issues, 1)
+		assert.False(t, HasErrors(issues))
+
+		dir := t.TempDir()
+		writeSchema(t, dir, "zero.yaml", "version: 0\ntypes: {}\n")
+		issues = NewRegistry(dir).Lint("zero")
+		require.Len(t, issues, 1)
+		assert.Equal(t, "[ERROR] zero: unsupported version 0; versions must be positive integers", issues[0].String())
+	})
+}
+
+func TestRegistry_LintAll(t *testing.T) {
+	dir := t.TempDir()
+	writeSchema(t, dir, "good.yaml", "version: 1\ntypes:\n  owner:\n    type: link\n    schema: person\n")
+	writeSchema(t, dir, "person.yaml", "version: 1\ntypes:\n  name:\n    type: string\n")
+	writeSchema(t, dir, "dangling.yaml", "version: 1\ntypes:\n  owner:\n    type: link\n    schema: nobody\n")
+	writeSchema(t, dir, "loop.yaml", "extends: loop\n")
+
+	registry := NewRegistry(dir)
+	names, err := registry.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dangling", "good", "loop", "person"}, names)
+
+	issues, err := registry.LintAll()
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "[ERROR] dangling: property 'owner': referenced schema 'nobody' does not exist", issues[0].String())
+	assert.Equal(t, "loop", issues[1].Schema)
+	assert.Contains(t, issues[1].Message, "schema cycle detected: loop -> loop")
+}
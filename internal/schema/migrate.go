@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Migration describes how to upgrade a record from one schema version to the next.
+type Migration struct {
+	From  int             `yaml:"from" json:"from"`
+	To    int             `yaml:"to" json:"to,omitempty"`
+	Steps []MigrationStep `yaml:"steps" json:"steps"`
+}
+
+// MigrationStep is a single change applied to a record. Exactly one of its
+// fields should be set.
+type MigrationStep struct {
+	Rename  *RenameStep  `yaml:"rename,omitempty" json:"rename,omitempty"`
+	MapEnum *MapEnumStep `yaml:"map_enum,omitempty" json:"map_enum,omitempty"`
+	Default *DefaultStep `yaml:"default,omitempty" json:"default,omitempty"`
+	Split   *SplitStep   `yaml:"split,omitempty" json:"split,omitempty"`
+}
+
+// RenameStep renames a property.
+type RenameStep struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+// MapEnumStep replaces an enum key with another in a property's value.
+type MapEnumStep struct {
+	Property string `yaml:"property" json:"property"`
+	From     string `yaml:"from" json:"from"`
+	To       string `yaml:"to" json:"to"`
+}
+
+// DefaultStep sets a property that the record does not have yet.
+type DefaultStep struct {
+	Property string      `yaml:"property" json:"property"`
+	Value    interface{} `yaml:"value" json:"value"`
+}
+
+// SplitStep splits one property into several.
+type SplitStep struct {
+	Property  string   `yaml:"property" json:"property"`
+	Into      []string `yaml:"into" json:"into"`
+	Separator string   `yaml:"separator" json:"separator,omitempty"`
+}
+
+// Migrate upgrades a record written for the given schema version to the
+// schema's current version. keys holds the record's property order, which is
+// preserved as far as possible. A version of 0 means the record does not
+// declare one and is taken to be current.
+func (s *Schema) Migrate(keys []string, record map[string]string, version int) ([]string, map[string]string, error) {
+	resultKeys := append([]string(nil), keys...)
+	result := make(map[string]string, len(record))
+	for key, value := range record {
+		result[key] = value
+	}
+
+	if version == 0 || version == s.Version {
+		return resultKeys, result, nil
+	}
+	if version > s.Version {
+		return nil, nil, fmt.Errorf("record version %d is newer than schema '%s' version %d", version, s.Name, s.Version)
+	}
+
+	for version < s.Version {
+		migration, ok := s.migrationFrom(version)
+		if !ok {
+			return nil, nil, fmt.Errorf("schema '%s' has no migration from version %d", s.Name, version)
+		}
+		for i, step := range migration.Steps {
+			var err error
+			resultKeys, err = step.apply(resultKeys, result)
+			if err != nil {
+				return nil, nil, fmt.Errorf("migration %d -> %d, step %d: %w", migration.From, migration.target(), i+1, err)
+			}
+		}
+		version = migration.target()
+	}
+	return resultKeys, result, nil
+}
+
+func (s *Schema) migrationFrom(version int) (Migration, bool) {
+	for _, migration := range s.Migrations {
+		if migration.From == version && migration.target() > version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// target returns the version a migration upgrades to. It defaults to From+1.
+func (m Migration) target() int {
+	if m.To == 0 {
+		return m.From + 1
+	}
+	return m.To
+}
+
+func (step MigrationStep) apply(keys []string, record map[string]string) ([]string, error) {
+	switch {
+	case step.Rename != nil:
+		value, ok := record[step.Rename.From]
+		if !ok {
+			return keys, nil
+		}
+		if _, exists := record[step.Rename.To]; exists {
+			return nil, fmt.Errorf("cannot rename '%s' to '%s': property already exists", step.Rename.From, step.Rename.To)
+		}
+		delete(record, step.Rename.From)
+		record[step.Rename.To] = value
+		return replaceKey(keys, step.Rename.From, step.Rename.To), nil
+
+	case step.MapEnum != nil:
+		value, ok := record[step.MapEnum.Property]
+		if !ok {
+			return keys, nil
+		}
+		values := strings.Split(value, ",")
+		for i, v := range values {
+			if strings.TrimSpace(v) == step.MapEnum.From {
+				values[i] = step.MapEnum.To
+			} else {
+				values[i] = strings.TrimSpace(v)
+			}
+		}
+		record[step.MapEnum.Property] = strings.Join(values, ", ")
+		return keys, nil
+
+	case step.Default != nil:
+		if _, ok := record[step.Default.Property]; ok {
+			return keys, nil
+		}
+		record[step.Default.Property] = fmt.Sprintf("%v", step.Default.Value)
+		return append(keys, step.Default.Property), nil
+
+	case step.Split != nil:
+		value, ok := record[step.Split.Property]
+		if !ok {
+			return keys, nil
+		}
+		if len(step.Split.Into) == 0 {
+			return nil, fmt.Errorf("split of '%s' has no target properties", step.Split.Property)
+		}
+		separator := step.Split.Separator
+		if separator == "" {
+			separator = ","
+		}
+		parts := strings.SplitN(value, separator, len(step.Split.Into))
+		for _, target := range step.Split.Into[:len(parts)] {
+			if _, exists := record[target]; exists && target != step.Split.Property {
+				return nil, fmt.Errorf("cannot split '%s' into '%s': property already exists", step.Split.Property, target)
+			}
+		}
+		delete(record, step.Split.Property)
+		for i, target := range step.Split.Into {
+			if i < len(parts) {
+				record[target] = strings.TrimSpace(parts[i])
+			}
+		}
+		return replaceKey(keys, step.Split.Property, step.Split.Into[:len(parts)]...), nil
+
+	default:
+		return nil, fmt.Errorf("empty migration step")
+	}
+}
+
+// checkMigrations rejects migration steps that cannot be applied, so that
+// mistakes surface when the schema is loaded rather than as odd values.
+func (s *Schema) checkMigrations() error {
+	for _, migration := range s.Migrations {
+		for i, step := range migration.Steps {
+			if step.Default != nil && step.Default.Value == nil {
+				return fmt.Errorf("migration %d -> %d, step %d: default of '%s' has no value", migration.From, migration.target(), i+1, step.Default.Property)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceKey substitutes old in keys with the given replacements, keeping its
+// position. Replacements already present elsewhere in keys are not duplicated.
+func replaceKey(keys []string, old string, replacements ...string) []string {
+	existing := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		existing[key] = true
+	}
+
+	var result []string
+	for _, key := range keys {
+		if key != old {
+			result = append(result, key)
+			continue
+		}
+		for _, replacement := range replacements {
+			if replacement == old || !existing[replacement] {
+				result = append(result, replacement)
+			}
+		}
+	}
+	return result
+}
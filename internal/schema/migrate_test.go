@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_Migrate(t *testing.T) {
+	schemaContent := `
+version: 3
+types:
+  color:
+    type: string
+migrations:
+  - from: 1
+    to: 2
+    steps:
+      - rename: {from: colour, to: color}
+      - map_enum: {property: status, from: wip, to: in_progress}
+  - from: 2
+    steps:
+      - default: {property: owner, value: nobody}
+      - split: {property: name, into: [first_name, last_name], separator: " "}
+`
+	var s Schema
+	require.NoError(t, yaml.Unmarshal([]byte(schemaContent), &s))
+	s.Name = "test"
+
+	t.Run("Applies every step in order", func(t *testing.T) {
+		keys := []string{"name", "colour", "status"}
+		record := map[string]string{"name": "Ada Lovelace", "colour": "red", "status": "draft, wip"}
+
+		keys, migrated, err := s.Migrate(keys, record, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first_name", "last_name", "color", "status", "owner"}, keys)
+		assert.Equal(t, map[string]string{
+			"first_name": "Ada",
+			"last_name":  "Lovelace",
+			"color":      "red",
+			"status":     "draft, in_progress",
+			"owner":      "nobody",
+		}, migrated)
+		assert.Equal(t, "Ada Lovelace", record["name"]) // input is not modified
+	})
+
+	t.Run("Current and undeclared versions are unchanged", func(t *testing.T) {
+		record := map[string]string{"colour": "red"}
+		_, migrated, err := s.Migrate([]string{"colour"}, record, 0)
+		require.NoError(t, err)
+		assert.Equal(t, record, migrated)
+
+		_, migrated, err = s.Migrate([]string{"colour"}, record, 3)
+		require.NoError(t, err)
+		assert.Equal(t, record, migrated)
+	})
+
+	t.Run("Newer record versions are rejected", func(t *testing.T) {
+		_, _, err := s.Migrate(nil, map[string]string{}, 4)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "record version 4 is newer than schema 'test' version 3")
+	})
+
+	t.Run("Missing migration", func(t *testing.T) {
+		gap := Schema{Name: "gap", Version: 3, Migrations: s.Migrations[1:]}
+		_, _, err := gap.Migrate(nil, map[string]string{}, 1)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "schema 'gap' has no migration from version 1")
+	})
+
+	t.Run("Rename onto an existing property fails", func(t *testing.T) {
+		_, _, err := s.Migrate([]string{"colour", "color"}, map[string]string{"colour": "red", "color": "blue"}, 1)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot rename 'colour' to 'color'")
+	})
+
+	t.Run("Split onto an existing property fails", func(t *testing.T) {
+		record := map[string]string{"name": "Ada Lovelace", "last_name": "Byron"}
+		_, _, err := s.Migrate([]string{"name", "last_name"}, record, 2)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot split 'name' into 'last_name': property already exists")
+	})
+}
+
+func TestLoadSchema_DefaultStepWithoutValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paint.yaml")
+	content := "version: 2\n" +
+		"migrations:\n" +
+		"  - from: 1\n" +
+		"    steps:\n" +
+		"      - default: {property: owner}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := LoadSchema(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "default of 'owner' has no value")
+}
@@ -0,0 +1,201 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Registry loads schemas by name from a directory and resolves their
+// extends, include and ref declarations.
+type Registry struct {
+	dir   string
+	cache map[string]*Schema
+}
+
+// NewRegistry creates a registry for the schemas in dir.
+func NewRegistry(dir string) *Registry {
+	return &Registry{
+		dir:   dir,
+		cache: make(map[string]*Schema),
+	}
+}
+
+// Path returns the file a schema name refers to. YAML files take precedence
+// over JSON files.
+func (r *Registry) Path(name string) string {
+	schemaFile := filepath.Join(r.dir, fmt.Sprintf("%s.yaml", name))
+	if _, err := os.Stat(schemaFile); os.IsNotExist(err) {
+		schemaFile = filepath.Join(r.dir, fmt.Sprintf("%s.json", name))
+	}
+	return schemaFile
+}
+
+// Get returns the fully resolved schema with the given name.
+func (r *Registry) Get(name string) (*Schema, error) {
+	return r.resolve(name, nil)
+}
+
+func (r *Registry) resolve(name string, chain []string) (*Schema, error) {
+	if s, ok := r.cache[name]; ok {
+		return s, nil
+	}
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("schema cycle detected: %s", strings.Join(append(chain, name), " -> "))
+		}
+	}
+	chain = append(chain, name)
+
+	raw, err := LoadSchema(r.Path(name))
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &Schema{
+		Name:                 name,
+		Version:              raw.Version,
+		versionSet:           raw.versionSet,
+		Strict:               raw.Strict,
+		AdditionalProperties: raw.AdditionalProperties,
+		Definitions:          make(map[string]Type),
+		Types:                make(map[string]Type),
+		Migrations:           raw.Migrations,
+	}
+
+	var bases []string
+	if raw.Extends != "" {
+		bases = append(bases, raw.Extends)
+	}
+	bases = append(bases, raw.Include...)
+	for _, baseName := range bases {
+		base, err := r.resolve(baseName, chain)
+		if err != nil {
+			return nil, fmt.Errorf("schema '%s': %w", name, err)
+		}
+		if baseName == raw.Extends {
+			if !resolved.versionSet {
+				resolved.Version = base.Version
+				resolved.versionSet = base.versionSet
+			}
+			if !raw.Strict && raw.AdditionalProperties == nil {
+				resolved.Strict = base.IsStrict()
+			}
+		}
+		for key, def := range base.Definitions {
+			resolved.Definitions[key] = def
+		}
+		for key, typeDef := range base.Types {
+			resolved.Types[key] = typeDef
+		}
+		resolved.OneOf = append(resolved.OneOf, base.OneOf...)
+	}
+	resolved.OneOf = append(resolved.OneOf, raw.OneOf...)
+
+	for key, def := range raw.Definitions {
+		resolved.Definitions[key] = def
+	}
+	for key := range resolved.Definitions {
+		def, err := resolveRef(resolved.Definitions[key], resolved.Definitions, []string{key})
+		if err != nil {
+			return nil, fmt.Errorf("schema '%s': definition '%s': %w", name, key, err)
+		}
+		resolved.Definitions[key] = def
+	}
+	for key, typeDef := range raw.Types {
+		typeDef, err := resolveRef(typeDef, resolved.Definitions, nil)
+		if err != nil {
+			return nil, fmt.Errorf("schema '%s': property '%s': %w", name, key, err)
+		}
+		resolved.Types[key] = typeDef
+	}
+
+	r.cache[name] = resolved
+	return resolved, nil
+}
+
+// resolveRef expands a type that references a shared definition. Fields set
+// on the referencing type override those of the definition.
+func resolveRef(typeDef Type, definitions map[string]Type, chain []string) (Type, error) {
+	if typeDef.Ref == "" {
+		return typeDef, nil
+	}
+	for _, seen := range chain {
+		if seen == typeDef.Ref {
+			return Type{}, fmt.Errorf("definition cycle detected: %s", strings.Join(append(chain, typeDef.Ref), " -> "))
+		}
+	}
+	def, ok := definitions[typeDef.Ref]
+	if !ok {
+		return Type{}, fmt.Errorf("unknown definition '%s'", typeDef.Ref)
+	}
+	base, err := resolveRef(def, definitions, append(chain, typeDef.Ref))
+	if err != nil {
+		return Type{}, err
+	}
+	return overlay(base, typeDef), nil
+}
+
+// overlay returns base with every non-zero field of override applied.
+func overlay(base, override Type) Type {
+	result := base
+	result.Ref = ""
+	if override.Label != "" {
+		result.Label = override.Label
+	}
+	if override.Description != "" {
+		result.Description = override.Description
+	}
+	if override.Example != nil {
+		result.Example = override.Example
+	}
+	if override.Required {
+		result.Required = true
+	}
+	if override.Type != "" {
+		result.Type = override.Type
+	}
+	if override.Default != nil {
+		result.Default = override.Default
+	}
+	if override.Keys != nil {
+		result.Keys = override.Keys
+	}
+	if override.Schema != "" {
+		result.Schema = override.Schema
+	}
+	if override.Unique {
+		result.Unique = true
+	}
+	if override.Pattern != "" {
+		result.Pattern = override.Pattern
+		result.compiled = override.compiled
+	}
+	if override.Minimum != nil {
+		result.Minimum = override.Minimum
+	}
+	if override.Maximum != nil {
+		result.Maximum = override.Maximum
+	}
+	if override.Compute != "" {
+		result.Compute = override.Compute
+		result.computed = override.computed
+	}
+	if override.Namespace != "" {
+		result.Namespace = override.Namespace
+	}
+	if override.Format != "" {
+		result.Format = override.Format
+	}
+	if override.Humanize {
+		result.Humanize = true
+	}
+	if override.RequiredIf != nil {
+		result.RequiredIf = override.RequiredIf
+	}
+	if override.ForbiddenUnless != nil {
+		result.ForbiddenUnless = override.ForbiddenUnless
+	}
+	return result
+}
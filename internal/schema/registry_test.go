@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSchema(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeSchema(t, dir, "common.yaml", `
+definitions:
+  status:
+    type: enum
+    keys:
+      open:
+        display: Open
+      closed:
+        display: Closed
+`)
+	writeSchema(t, dir, "base.yaml", `
+version: 1
+include: [common]
+types:
+  owner:
+    type: string
+    required: true
+  created:
+    type: date
+  status:
+    ref: status
+`)
+	writeSchema(t, dir, "book.yaml", `
+extends: base
+types:
+  owner:
+    type: string
+    default: library
+  isbn:
+    type: string
+  state:
+    ref: status
+    required: true
+`)
+	writeSchema(t, dir, "cycle_a.yaml", "extends: cycle_b\n")
+	writeSchema(t, dir, "cycle_b.yaml", "extends: cycle_a\n")
+	writeSchema(t, dir, "bad_ref.yaml", "types:\n  x:\n    ref: missing\n")
+
+	registry := NewRegistry(dir)
+
+	t.Run("Extends and include", func(t *testing.T) {
+		book, err := registry.Get("book")
+		require.NoError(t, err)
+		assert.Equal(t, "book", book.Name)
+		assert.Equal(t, 1, book.Version) // inherited from base
+
+		assert.Contains(t, book.Types, "created")
+		assert.Contains(t, book.Types, "isbn")
+		assert.Equal(t, "library", book.Types["owner"].Default)
+		assert.False(t, book.Types["owner"].Required) // overridden, not merged
+
+		assert.Equal(t, "enum", book.Types["status"].Type)
+		assert.Equal(t, "enum", book.Types["state"].Type)
+		assert.True(t, book.Types["state"].Required)
+		assert.Equal(t, "Closed", book.Types["state"].Keys["closed"].Display)
+	})
+
+	t.Run("Resolved schema validates records", func(t *testing.T) {
+		book, err := registry.Get("book")
+		require.NoError(t, err)
+		transformed, err := book.ValidateAndTransform(map[string]string{"state": "open"})
+		require.NoError(t, err)
+		assert.Equal(t, "library", transformed["owner"])
+		assert.Equal(t, "[[state/Open]]", transformed["state"])
+	})
+
+	t.Run("Cycle detection", func(t *testing.T) {
+		_, err := registry.Get("cycle_a")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "schema cycle detected: cycle_a -> cycle_b -> cycle_a")
+	})
+
+	t.Run("Unknown definition", func(t *testing.T) {
+		_, err := registry.Get("bad_ref")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown definition 'missing'")
+	})
+}
@@ -1,10 +1,15 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,42 +17,147 @@ import (
 
 // Schema represents the structure of a schema file.
 type Schema struct {
-	Version int             `yaml:"version"`
-	Types   map[string]Type `yaml:"types"`
+	Name                 string          `yaml:"-"`
+	Version              int             `yaml:"version"`
+	Extends              string          `yaml:"extends,omitempty"`
+	Include              []string        `yaml:"include,omitempty"`
+	Strict               bool            `yaml:"strict,omitempty"`
+	AdditionalProperties *bool           `yaml:"additional_properties,omitempty"`
+	Definitions          map[string]Type `yaml:"definitions,omitempty"`
+	Types                map[string]Type `yaml:"types"`
+	OneOf                []OneOfGroup    `yaml:"one_of,omitempty"`
+	Migrations           []Migration     `yaml:"migrations,omitempty"`
+
+	// versionSet records whether the file declares a version, so that Lint
+	// can tell "version: 0" from a missing version.
+	versionSet bool
 }
 
 // Type represents the type definition for a property.
 type Type struct {
-	Required bool               `yaml:"required"`
-	Type     string             `yaml:"type"`
-	Default  interface{}        `yaml:"default"`
-	Keys     map[string]EnumKey `yaml:"keys"`
-	Schema   string             `yaml:"schema"`
+	Label       string      `yaml:"label,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Example     interface{} `yaml:"example,omitempty"`
+
+	Required bool               `yaml:"required,omitempty"`
+	Type     string             `yaml:"type,omitempty"`
+	Default  interface{}        `yaml:"default,omitempty"`
+	Keys     map[string]EnumKey `yaml:"keys,omitempty"`
+	Schema   string             `yaml:"schema,omitempty"`
+	Unique   bool               `yaml:"unique,omitempty"`
+	Ref      string             `yaml:"ref,omitempty"`
+	Pattern  string             `yaml:"pattern,omitempty"`
+	Minimum  *float64           `yaml:"minimum,omitempty"`
+	Maximum  *float64           `yaml:"maximum,omitempty"`
+	Compute  string             `yaml:"compute,omitempty"`
+
+	Namespace string `yaml:"namespace,omitempty"`
+	Format    string `yaml:"format,omitempty"`
+	Humanize  bool   `yaml:"humanize,omitempty"`
+
+	RequiredIf      Condition `yaml:"required_if,omitempty"`
+	ForbiddenUnless Condition `yaml:"forbidden_unless,omitempty"`
+
+	// compiled is Pattern, compiled when the schema is loaded.
+	compiled *regexp.Regexp
+	// computed is Compute, parsed when the schema is loaded.
+	computed *template.Template
 }
 
 // EnumKey represents a key in an enum.
 type EnumKey struct {
-	Display string `yaml:"display"`
+	Display     string   `yaml:"display,omitempty" json:"display,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Deprecated  bool     `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReplacedBy  string   `yaml:"replaced_by,omitempty" json:"replaced_by,omitempty"`
 }
 
-// LoadSchema loads a schema from a YAML file.
+// LoadSchema loads a schema from a YAML file. JSON files written as JSON
+// Schema documents are converted with ImportJSONSchema.
 func LoadSchema(path string) (*Schema, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema file %s: %w", path, err)
 	}
 
-	var schema Schema
-	err = yaml.Unmarshal(data, &schema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal schema file %s: %w", path, err)
+	var schema *Schema
+	if filepath.Ext(path) == ".json" && isJSONSchema(data) {
+		schema, err = ImportJSONSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import JSON schema file %s: %w", path, err)
+		}
+		schema.versionSet = true
+	} else {
+		schema = &Schema{}
+		err = yaml.Unmarshal(data, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal schema file %s: %w", path, err)
+		}
+		var probe struct {
+			Version *int `yaml:"version"`
+		}
+		if err := yaml.Unmarshal(data, &probe); err == nil {
+			schema.versionSet = probe.Version != nil
+		}
+	}
+	schema.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := schema.checkMigrations(); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %w", path, err)
+	}
+	compilePatterns(schema.Types)
+	compilePatterns(schema.Definitions)
+	parseComputes(schema.Types)
+	parseComputes(schema.Definitions)
+
+	return schema, nil
+}
+
+// compilePatterns compiles the pattern of every type once. Invalid patterns
+// are left for Lint and validation to report.
+func compilePatterns(types map[string]Type) {
+	for key, typeDef := range types {
+		if typeDef.Pattern == "" {
+			continue
+		}
+		if compiled, err := regexp.Compile(typeDef.Pattern); err == nil {
+			typeDef.compiled = compiled
+			types[key] = typeDef
+		}
+	}
+}
+
+// WithDefaults returns a copy of record with the default value filled in for
+// every missing property that has one.
+func (s *Schema) WithDefaults(record map[string]string) map[string]string {
+	result := make(map[string]string, len(record))
+	for key, value := range record {
+		result[key] = value
+	}
+	for key, typeDef := range s.Types {
+		if _, exists := result[key]; !exists && typeDef.Default != nil {
+			result[key] = fmt.Sprintf("%v", typeDef.Default)
+		}
 	}
+	return result
+}
 
-	return &schema, nil
+// IsStrict reports whether the schema rejects properties it does not declare.
+func (s *Schema) IsStrict() bool {
+	if s.AdditionalProperties != nil {
+		return !*s.AdditionalProperties
+	}
+	return s.Strict
 }
 
 // ValidateAndTransform validates and transforms a record based on the schema.
 func (s *Schema) ValidateAndTransform(record map[string]string) (map[string]string, error) {
+	if s.IsStrict() {
+		if err := s.checkUndeclared(record); err != nil {
+			return nil, err
+		}
+	}
+
 	result := make(map[string]string)
 	for key, value := range record {
 		result[key] = value
@@ -70,45 +180,181 @@ func (s *Schema) ValidateAndTransform(record map[string]string) (map[string]stri
 			continue
 		}
 
-		switch typeDef.Type {
-		case "number":
-			if _, err := strconv.ParseFloat(value, 64); err != nil {
-				return nil, fmt.Errorf("property '%s' with value '%s' is not a valid number", key, value)
-			}
-		case "boolean":
-			if _, err := strconv.ParseBool(value); err != nil {
-				return nil, fmt.Errorf("property '%s' with value '%s' is not a valid boolean", key, value)
+		transformed, err := typeDef.transform(key, value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = transformed
+	}
+
+	if err := s.checkConditions(record); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ValidateAndTransformDecoded is ValidateAndTransform for records read from a
+// format with types of its own, such as YAML, JSON or TOML. decoded holds
+// values as the format decoded them; each is checked against its declared
+// type before record, the same values in string form, is validated. A number
+// must be written as a number and a boolean as a boolean, and only enum
+// properties take lists.
+func (s *Schema) ValidateAndTransformDecoded(record map[string]string, decoded map[string]interface{}) (map[string]string, error) {
+	keys := make([]string, 0, len(decoded))
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if typeDef, ok := s.Types[key]; ok {
+			if err := typeDef.checkDecoded(key, decoded[key]); err != nil {
+				return nil, err
 			}
-		case "string":
-			// No validation needed for string type
-		case "enum":
-			values := strings.Split(value, ",")
-			var transformedValues []string
-			for _, v := range values {
-				trimmedValue := strings.TrimSpace(v)
-				if enumKey, ok := typeDef.Keys[trimmedValue]; ok {
-					displayValue := enumKey.Display
-					if displayValue == "" {
-						displayValue = trimmedValue
-					}
-					transformedValues = append(transformedValues, fmt.Sprintf("[[%s/%s]]", key, displayValue))
-				} else {
-					return nil, fmt.Errorf("property '%s' with value '%s' contains an invalid enum key: %s", key, value, trimmedValue)
-				}
+		}
+	}
+	return s.ValidateAndTransform(record)
+}
+
+// checkDecoded checks that a decoded value has the Go type its property type
+// calls for. Dates may be strings or time.Time.
+func (typeDef Type) checkDecoded(key string, value interface{}) error {
+	if _, ok := value.([]interface{}); ok {
+		if typeDef.Type != "enum" {
+			return fmt.Errorf("property '%s' is a list, but only enum properties take several values", key)
+		}
+		return nil
+	}
+
+	var ok bool
+	switch typeDef.Type {
+	case "number":
+		switch value.(type) {
+		case int, int64, float64, json.Number:
+			ok = true
+		}
+	case "boolean":
+		_, ok = value.(bool)
+	case "date":
+		switch value.(type) {
+		case string, time.Time:
+			ok = true
+		}
+	default:
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("property '%s' with value '%v' is written as %s, not as a %s", key, value, decodedKind(value), typeDef.Type)
+	}
+	return nil
+}
+
+// decodedKind names the kind of a decoded value for error messages.
+func decodedKind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "text"
+	case bool:
+		return "a boolean"
+	case int, int64, float64, json.Number:
+		return "a number"
+	case time.Time:
+		return "a date"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// transform validates a single value against the type and returns its
+// Logseq representation.
+func (typeDef Type) transform(key, value string) (string, error) {
+	if typeDef.Pattern != "" {
+		pattern := typeDef.compiled
+		if pattern == nil {
+			var err error
+			if pattern, err = regexp.Compile(typeDef.Pattern); err != nil {
+				return "", fmt.Errorf("property '%s' has an invalid pattern '%s': %w", key, typeDef.Pattern, err)
 			}
-			result[key] = strings.Join(transformedValues, " ")
-		case "link":
-			// In a real-world scenario, you might want to validate the link format.
-			// For now, we just check if it's a string.
-		case "date":
-			if _, err := time.Parse("2006-01-02", value); err != nil {
-				return nil, fmt.Errorf("property '%s' with value '%s' is not a valid date in YYYY-MM-DD format", key, value)
+		}
+		if !pattern.MatchString(value) {
+			return "", fmt.Errorf("property '%s' with value '%s' does not match pattern '%s'", key, value, typeDef.Pattern)
+		}
+	}
+
+	switch typeDef.Type {
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("property '%s' with value '%s' is not a valid number", key, value)
+		}
+		if typeDef.Minimum != nil && number < *typeDef.Minimum {
+			return "", fmt.Errorf("property '%s' with value '%s' is less than the minimum %v", key, value, *typeDef.Minimum)
+		}
+		if typeDef.Maximum != nil && number > *typeDef.Maximum {
+			return "", fmt.Errorf("property '%s' with value '%s' is greater than the maximum %v", key, value, *typeDef.Maximum)
+		}
+		return value, nil
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "", fmt.Errorf("property '%s' with value '%s' is not a valid boolean", key, value)
+		}
+		return value, nil
+	case "string":
+		// No validation needed for string type
+		return value, nil
+	case "enum":
+		values := strings.Split(value, ",")
+		var transformedValues []string
+		for _, v := range values {
+			trimmedValue := strings.TrimSpace(v)
+			if name, enumKey, ok := typeDef.lookupEnum(trimmedValue); ok {
+				transformedValues = append(transformedValues, typeDef.enumReference(key, name, enumKey))
+			} else {
+				return "", fmt.Errorf("property '%s' with value '%s' is not a valid enum key: %s", key, value, trimmedValue)
 			}
-			result[key] = fmt.Sprintf("[[%s]]", value)
-		default:
-			return nil, fmt.Errorf("unknown type '%s' for property '%s'", typeDef.Type, key)
 		}
+		return strings.Join(transformedValues, " "), nil
+	case "link":
+		// In a real-world scenario, you might want to validate the link format.
+		// For now, we just check if it's a string.
+		return value, nil
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("property '%s' with value '%s' is not a valid date in YYYY-MM-DD format", key, value)
+		}
+		return fmt.Sprintf("[[%s]]", value), nil
+	default:
+		return "", fmt.Errorf("unknown type '%s' for property '%s'", typeDef.Type, key)
 	}
+}
 
-	return result, nil
+// checkUndeclared reports every property in record that the schema does not
+// declare, suggesting the closest declared key for likely misspellings.
+func (s *Schema) checkUndeclared(record map[string]string) error {
+	declared := make([]string, 0, len(s.Types))
+	for key := range s.Types {
+		declared = append(declared, key)
+	}
+	sort.Strings(declared)
+
+	var unknown []string
+	for key := range record {
+		if _, ok := s.Types[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	messages := make([]string, 0, len(unknown))
+	for _, key := range unknown {
+		message := fmt.Sprintf("unknown property '%s'", key)
+		if suggestion := suggest(key, declared); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
 }
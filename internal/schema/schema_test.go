@@ -123,3 +123,65 @@ types:
 		assert.Contains(t, err.Error(), "is not a valid date in YYYY-MM-DD format")
 	})
 }
+
+func TestContext_CheckUnique(t *testing.T) {
+	schema := &Schema{
+		Name: "book",
+		Types: map[string]Type{
+			"isbn":  {Type: "string", Unique: true},
+			"code":  {Type: "string", Unique: true},
+			"title": {Type: "string"},
+		},
+	}
+	ctx := NewContext()
+
+	assert.NoError(t, ctx.CheckUnique(schema, "a/index.ini", map[string]string{"isbn": "123", "title": "Same"}))
+	assert.NoError(t, ctx.CheckUnique(schema, "b/index.ini", map[string]string{"isbn": "456", "title": "Same"}))
+
+	err := ctx.CheckUnique(schema, "c/index.ini", map[string]string{"isbn": " 123 ", "code": "X"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unique property 'isbn' with value '123' in c/index.ini is already used by a/index.ini")
+
+	// A rejected record does not claim its values.
+	assert.NoError(t, ctx.CheckUnique(schema, "d/index.ini", map[string]string{"code": "X"}))
+}
+
+func TestSchema_Strict(t *testing.T) {
+	disallow := false
+	schema := &Schema{
+		AdditionalProperties: &disallow,
+		Types: map[string]Type{
+			"title":  {Type: "string"},
+			"author": {Type: "string"},
+		},
+	}
+	assert.True(t, schema.IsStrict())
+
+	t.Run("Declared properties pass", func(t *testing.T) {
+		_, err := schema.ValidateAndTransform(map[string]string{"title": "Dune", "author": "Herbert"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Undeclared properties are reported with suggestions", func(t *testing.T) {
+		_, err := schema.ValidateAndTransform(map[string]string{"titel": "Dune", "publisher": "Chilton"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown property 'titel' (did you mean 'title'?)")
+		assert.Contains(t, err.Error(), "unknown property 'publisher'")
+		assert.NotContains(t, err.Error(), "'publisher' (did you mean")
+	})
+
+	t.Run("Non-strict schemas keep undeclared properties", func(t *testing.T) {
+		lenient := &Schema{Types: schema.Types}
+		transformed, err := lenient.ValidateAndTransform(map[string]string{"titel": "Dune"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Dune", transformed["titel"])
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("title", "title"))
+	assert.Equal(t, 2, editDistance("titel", "title"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, "author", suggest("auther", []string{"author", "title"}))
+	assert.Equal(t, "", suggest("zzz", []string{"author", "title"}))
+}
@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnumValue is a single resolved value of an enum property.
+type EnumValue struct {
+	// Key is the canonical enum key, after resolving aliases and replacements.
+	Key string
+	// Display is the display name, falling back to the key.
+	Display string
+	// Page is the Logseq page the value links to.
+	Page string
+}
+
+// String returns the display name, so enum values print naturally in templates.
+func (v EnumValue) String() string {
+	return v.Display
+}
+
+// TypedValues converts a validated record into typed values: float64 for
+// numbers, bool for booleans, time.Time for dates, []EnumValue for enums and
+// string for everything else, including undeclared properties. Defaults are
+// applied for missing properties.
+func (s *Schema) TypedValues(record map[string]string) map[string]interface{} {
+	record = s.WithDefaults(record)
+	values := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		values[key] = s.Types[key].typedValue(key, value)
+	}
+	return values
+}
+
+// typedValue converts one raw value. Values that do not parse are returned
+// unchanged as strings.
+func (typeDef Type) typedValue(key, value string) interface{} {
+	switch typeDef.Type {
+	case "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "date":
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			return t
+		}
+	case "enum":
+		var enumValues []EnumValue
+		for _, v := range strings.Split(value, ",") {
+			name, enumKey, ok := typeDef.lookupEnum(strings.TrimSpace(v))
+			if !ok {
+				return value
+			}
+			enumValues = append(enumValues, EnumValue{
+				Key:     name,
+				Display: typeDef.enumDisplay(name, enumKey),
+				Page:    typeDef.enumPage(key, name, enumKey),
+			})
+		}
+		return enumValues
+	}
+	return value
+}
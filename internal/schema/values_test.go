@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_TypedValues(t *testing.T) {
+	s := &Schema{
+		Types: map[string]Type{
+			"count": {Type: "number"},
+			"done":  {Type: "boolean", Default: false},
+			"due":   {Type: "date"},
+			"tags": {Type: "enum", Humanize: true, Keys: map[string]EnumKey{
+				"a":         {Display: "Alpha", Aliases: []string{"first"}},
+				"long_name": {},
+			}},
+			"title": {Type: "string"},
+		},
+	}
+
+	values := s.TypedValues(map[string]string{
+		"count": "4.5",
+		"due":   "2025-09-21",
+		"tags":  "first, long_name",
+		"title": "Hello",
+		"extra": "kept",
+	})
+
+	assert.Equal(t, 4.5, values["count"])
+	assert.Equal(t, false, values["done"]) // from default
+	assert.Equal(t, time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC), values["due"])
+	assert.Equal(t, []EnumValue{
+		{Key: "a", Display: "Alpha", Page: "tags/Alpha"},
+		{Key: "long_name", Display: "Long Name", Page: "tags/Long Name"},
+	}, values["tags"])
+	assert.Equal(t, "Hello", values["title"])
+	assert.Equal(t, "kept", values["extra"])
+}
+
+func TestSchema_ValidateAndTransformDecoded(t *testing.T) {
+	s := &Schema{Types: map[string]Type{
+		"count": {Type: "number"},
+		"done":  {Type: "boolean"},
+		"due":   {Type: "date"},
+		"tags":  {Type: "enum", Keys: map[string]EnumKey{"a": {}, "b": {}}},
+		"title": {Type: "string"},
+	}}
+	record := map[string]string{"count": "3", "done": "true", "due": "2025-09-21", "tags": "a, b", "title": "7"}
+
+	transformed, err := s.ValidateAndTransformDecoded(record, map[string]interface{}{
+		"count": json.Number("3"),
+		"done":  true,
+		"due":   time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC),
+		"tags":  []interface{}{"a", "b"},
+		"title": int64(7),
+		"other": []interface{}{"x"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "[[2025-09-21]]", transformed["due"])
+
+	for _, decoded := range []map[string]interface{}{
+		{"count": "3"},
+		{"done": "true"},
+		{"due": int64(20250921)},
+		{"title": []interface{}{"7"}},
+	} {
+		_, err := s.ValidateAndTransformDecoded(record, decoded)
+		assert.Error(t, err, "%v", decoded)
+	}
+}